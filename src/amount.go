@@ -0,0 +1,220 @@
+package isbasi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const amountScale = 6
+
+var ErrInvalidAmount = errors.New("isbasi: invalid amount")
+
+var amountFactor = big.NewInt(int64(math.Pow10(amountScale)))
+
+type Amount struct {
+	units *big.Int
+}
+
+func NewAmount(value string) (Amount, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Amount{}, nil
+	}
+	rat, ok := new(big.Rat).SetString(normalizeDecimal(value))
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	return amountFromRat(rat), nil
+}
+
+func MustAmount(value string) Amount {
+	amount, err := NewAmount(value)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+func AmountFromInt(value int64) Amount {
+	return Amount{units: new(big.Int).Mul(big.NewInt(value), amountFactor)}
+}
+
+func AmountFromFloat(value float64) (Amount, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Amount{}, fmt.Errorf("%w: %v", ErrInvalidAmount, value)
+	}
+	return NewAmount(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func normalizeDecimal(value string) string {
+	comma, dot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && comma > dot:
+		return strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+	case comma >= 0 && dot >= 0:
+		return strings.ReplaceAll(value, ",", "")
+	case strings.Count(value, ",") > 1:
+		return strings.ReplaceAll(value, ",", "")
+	case comma >= 0:
+		return strings.ReplaceAll(value, ",", ".")
+	case strings.Count(value, ".") > 1:
+		return strings.ReplaceAll(value, ".", "")
+	}
+	return value
+}
+
+func amountFromRat(rat *big.Rat) Amount {
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(amountFactor))
+	return Amount{units: roundHalfAway(scaled.Num(), scaled.Denom())}
+}
+
+func roundHalfAway(num, denom *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+	twice := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	if twice.Cmp(new(big.Int).Abs(denom)) >= 0 {
+		if num.Sign()*denom.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func (a Amount) int() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return a.units
+}
+
+func (a Amount) rat() *big.Rat {
+	return new(big.Rat).SetFrac(a.int(), amountFactor)
+}
+
+func (a Amount) Add(b Amount) Amount {
+	return Amount{units: new(big.Int).Add(a.int(), b.int())}
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{units: new(big.Int).Sub(a.int(), b.int())}
+}
+
+func (a Amount) Neg() Amount {
+	return Amount{units: new(big.Int).Neg(a.int())}
+}
+
+func (a Amount) Abs() Amount {
+	return Amount{units: new(big.Int).Abs(a.int())}
+}
+
+func (a Amount) Mul(b Amount) Amount {
+	return amountFromRat(new(big.Rat).Mul(a.rat(), b.rat()))
+}
+
+func (a Amount) Div(b Amount) (Amount, error) {
+	if b.IsZero() {
+		return Amount{}, fmt.Errorf("%w: division by zero", ErrInvalidAmount)
+	}
+	return amountFromRat(new(big.Rat).Quo(a.rat(), b.rat())), nil
+}
+
+func (a Amount) MulInt(n int64) Amount {
+	return Amount{units: new(big.Int).Mul(a.int(), big.NewInt(n))}
+}
+
+func (a Amount) Percent(rate Amount) Amount {
+	return amountFromRat(new(big.Rat).Quo(new(big.Rat).Mul(a.rat(), rate.rat()), big.NewRat(100, 1)))
+}
+
+func (a Amount) AddPercent(rate Amount) Amount {
+	return a.Add(a.Percent(rate))
+}
+
+func (a Amount) Convert(exchangeRate Amount) Amount {
+	return a.Mul(exchangeRate).Round(2)
+}
+
+func (a Amount) ConvertBack(exchangeRate Amount) (Amount, error) {
+	amount, err := a.Div(exchangeRate)
+	if err != nil {
+		return Amount{}, err
+	}
+	return amount.Round(2), nil
+}
+
+func (a Amount) Round(places int) Amount {
+	if places >= amountScale || places < 0 {
+		return a
+	}
+	step := big.NewInt(int64(math.Pow10(amountScale - places)))
+	units := roundHalfAway(a.int(), step)
+	return Amount{units: units.Mul(units, step)}
+}
+
+func (a Amount) Cmp(b Amount) int {
+	return a.int().Cmp(b.int())
+}
+
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+func (a Amount) Sign() int {
+	return a.int().Sign()
+}
+
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+func (a Amount) Float64() float64 {
+	f, _ := a.rat().Float64()
+	return f
+}
+
+func (a Amount) StringFixed(places int) string {
+	if places > amountScale {
+		places = amountScale
+	}
+	return a.Round(places).rat().FloatString(places)
+}
+
+func (a Amount) String() string {
+	text := a.rat().FloatString(amountScale)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*a = Amount{}
+		return nil
+	}
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+	amount, err := NewAmount(value)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
@@ -0,0 +1,89 @@
+package isbasi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestAmountParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1234.5", "1234.5"},
+		{"1234,5", "1234.5"},
+		{"1.234,5", "1234.5"},
+		{"1,234.5", "1234.5"},
+		{"1.234.567", "1234567"},
+		{"1,234,567", "1234567"},
+		{"-0,125", "-0.125"},
+	}
+	for _, tt := range tests {
+		amount, err := isbasi.NewAmount(tt.in)
+		if err != nil {
+			t.Errorf("NewAmount(%q): %v", tt.in, err)
+			continue
+		}
+		if got := amount.String(); got != tt.want {
+			t.Errorf("NewAmount(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		var decoded isbasi.Amount
+		data, _ := json.Marshal(tt.in)
+		if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Equal(amount) {
+			t.Errorf("Unmarshal(%s) = %s, %v, want %s", data, decoded, err, tt.want)
+		}
+	}
+}
+
+func TestAmountLargeValues(t *testing.T) {
+	large := isbasi.MustAmount("9000000000000")
+	tests := []struct {
+		name string
+		got  isbasi.Amount
+		want string
+	}{
+		{"Add", large.Add(large), "18000000000000"},
+		{"Sub", large.Neg().Sub(large), "-18000000000000"},
+		{"Mul", large.Mul(large), "81000000000000000000000000"},
+		{"MulInt", large.MulInt(2), "18000000000000"},
+		{"FromInt", isbasi.AmountFromInt(math.MaxInt64), "9223372036854775807"},
+		{"Parse", isbasi.MustAmount("12345678901234567.125"), "12345678901234567.125"},
+		{"Cancel", large.Add(large.Neg()), "0"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	price := isbasi.MustAmount("99.90")
+	if got := price.MulInt(3).AddPercent(isbasi.AmountFromInt(20)).Round(2); got.String() != "359.64" {
+		t.Errorf("got %s, want 359.64", got)
+	}
+	if got := isbasi.MustAmount("0.125").Round(2); got.String() != "0.13" {
+		t.Errorf("got %s, want 0.13", got)
+	}
+	if got := isbasi.MustAmount("-0.125").Round(2); got.String() != "-0.13" {
+		t.Errorf("got %s, want -0.13", got)
+	}
+	if _, err := price.Div(isbasi.Amount{}); !errors.Is(err, isbasi.ErrInvalidAmount) {
+		t.Errorf("got %v, want ErrInvalidAmount on division by zero", err)
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1)} {
+		if _, err := isbasi.AmountFromFloat(value); !errors.Is(err, isbasi.ErrInvalidAmount) {
+			t.Errorf("AmountFromFloat(%v): got %v, want ErrInvalidAmount", value, err)
+		}
+	}
+	if got, err := isbasi.AmountFromFloat(0.1); err != nil || got.String() != "0.1" {
+		t.Errorf("AmountFromFloat(0.1) = %s, %v, want 0.1", got, err)
+	}
+	if !isbasi.AmountFromInt(0).IsZero() || !isbasi.AmountFromInt(1).Sub(isbasi.AmountFromInt(1)).IsZero() {
+		t.Error("zero amounts not reported as zero")
+	}
+}
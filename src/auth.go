@@ -0,0 +1,102 @@
+package isbasi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const tokenExpirySkew = 30 * time.Second
+
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (*Login, error)
+}
+
+type CredentialsFunc func(ctx context.Context) (*Login, error)
+
+func (f CredentialsFunc) Credentials(ctx context.Context) (*Login, error) {
+	return f(ctx)
+}
+
+func StaticCredentials(login *Login) CredentialProvider {
+	return CredentialsFunc(func(ctx context.Context) (*Login, error) {
+		return login, nil
+	})
+}
+
+func WithCredentials(provider CredentialProvider) Option {
+	return func(api *API) {
+		api.credentials = provider
+	}
+}
+
+func (api *API) SetCredentials(provider CredentialProvider) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.credentials = provider
+}
+
+func (api *API) credentialProvider() CredentialProvider {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.credentials
+}
+
+func (api *API) refresh(ctx context.Context, stale string) error {
+	api.authMu.Lock()
+	defer api.authMu.Unlock()
+	s := api.session()
+	if s.authToken != stale {
+		return nil
+	}
+	credentials := api.credentialProvider()
+	if credentials == nil {
+		return fmt.Errorf("failed to refresh token: no credentials")
+	}
+	login, err := credentials.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if login.TenantId == "" && s.tenantId != "" {
+		tenantLogin := *login
+		tenantLogin.TenantId = s.tenantId
+		login = &tenantLogin
+	}
+	if _, err := api.Login(ctx, login); err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return nil
+}
+
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+func tokenExpired(token string) bool {
+	if token == "" {
+		return true
+	}
+	exp, ok := tokenExpiry(token)
+	return ok && time.Now().Add(tokenExpirySkew).After(exp)
+}
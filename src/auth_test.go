@@ -0,0 +1,26 @@
+package isbasi_test
+
+import (
+	"context"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestRefreshReturnsCleanResponse(t *testing.T) {
+	server := newServer(t)
+	api := server.Client(isbasi.WithCredentials(isbasi.StaticCredentials(server.Login())))
+	ctx := context.Background()
+	created, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "C1", Name: "Test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.ExpireTokens()
+	res, err := api.GetFirm(ctx, created.Data.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError || res.Message != "Success" || res.Extra != nil || res.Data == nil || res.Data.Code != "C1" {
+		t.Fatalf("got stale envelope after refresh: %+v", res)
+	}
+}
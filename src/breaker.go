@@ -0,0 +1,190 @@
+package isbasi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("isbasi: circuit open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+type CircuitBreakerSettings struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+	OnStateChange    func(host string, from, to CircuitState)
+}
+
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+	mu       sync.Mutex
+	hosts    map[string]*circuit
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+}
+
+type CircuitOpenError struct {
+	Host    string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("isbasi: circuit open for %s until %s", e.Host, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = 1
+	}
+	return &CircuitBreaker{settings: settings, hosts: make(map[string]*circuit)}
+}
+
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(api *API) {
+		api.breaker = breaker
+	}
+}
+
+func (b *CircuitBreaker) State(host string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.hosts[host]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+			if err := b.allow(host); err != nil {
+				return nil, err
+			}
+			res, err := next(req)
+			b.done(host, failed(req.Context(), res, err))
+			return res, err
+		}
+	}
+}
+
+func (b *CircuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	var notify func()
+	defer func() {
+		b.mu.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+	c, ok := b.hosts[host]
+	if !ok {
+		c = new(circuit)
+		b.hosts[host] = c
+	}
+	switch c.state {
+	case CircuitOpen:
+		retryAt := c.openedAt.Add(b.settings.OpenTimeout)
+		if time.Now().Before(retryAt) {
+			return &CircuitOpenError{Host: host, RetryAt: retryAt}
+		}
+		notify = b.transition(host, c, CircuitHalfOpen)
+	case CircuitHalfOpen:
+		if c.inFlight >= b.settings.HalfOpenRequests {
+			return &CircuitOpenError{Host: host, RetryAt: time.Now()}
+		}
+	}
+	c.inFlight++
+	return nil
+}
+
+func (b *CircuitBreaker) done(host string, failure bool) {
+	b.mu.Lock()
+	var notify func()
+	defer func() {
+		b.mu.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+	c := b.hosts[host]
+	c.inFlight--
+	switch {
+	case failure && c.state == CircuitHalfOpen:
+		notify = b.transition(host, c, CircuitOpen)
+	case failure:
+		c.failures++
+		if c.state == CircuitClosed && c.failures >= b.settings.FailureThreshold {
+			notify = b.transition(host, c, CircuitOpen)
+		}
+	case c.state == CircuitHalfOpen:
+		c.successes++
+		if c.successes >= b.settings.HalfOpenRequests {
+			notify = b.transition(host, c, CircuitClosed)
+		}
+	default:
+		c.failures = 0
+	}
+}
+
+func (b *CircuitBreaker) transition(host string, c *circuit, state CircuitState) func() {
+	from := c.state
+	c.state = state
+	c.failures = 0
+	c.successes = 0
+	if state == CircuitOpen {
+		c.openedAt = time.Now()
+	}
+	if b.settings.OnStateChange == nil || from == state {
+		return nil
+	}
+	return func() {
+		b.settings.OnStateChange(host, from, state)
+	}
+}
+
+func failed(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return res.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,145 @@
+package isbasi_test
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+const breakerHost = "api.example.com"
+
+type transitions struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+func (t *transitions) record(host string, from, to isbasi.CircuitState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seen = append(t.seen, from.String()+"->"+to.String())
+}
+
+func (t *transitions) get() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.seen...)
+}
+
+func newBreaker() (*isbasi.CircuitBreaker, *transitions) {
+	seen := new(transitions)
+	breaker := isbasi.NewCircuitBreaker(isbasi.CircuitBreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		HalfOpenRequests: 1,
+		OnStateChange:    seen.record,
+	})
+	return breaker, seen
+}
+
+func breakerCall(breaker *isbasi.CircuitBreaker, status int) error {
+	handler := breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "https://"+breakerHost+"/firms", nil)
+	_, err := handler(req)
+	return err
+}
+
+func openBreaker(t *testing.T, breaker *isbasi.CircuitBreaker) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		if err := breakerCall(breaker, http.StatusInternalServerError); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state := breaker.State(breakerHost); state != isbasi.CircuitOpen {
+		t.Fatalf("got state %s, want open", state)
+	}
+}
+
+func TestCircuitBreakerOpensAtThreshold(t *testing.T) {
+	breaker, seen := newBreaker()
+	breakerCall(breaker, http.StatusInternalServerError)
+	if state := breaker.State(breakerHost); state != isbasi.CircuitClosed {
+		t.Fatalf("got state %s after one failure, want closed", state)
+	}
+	breakerCall(breaker, http.StatusInternalServerError)
+	if state := breaker.State(breakerHost); state != isbasi.CircuitOpen {
+		t.Fatalf("got state %s after two failures, want open", state)
+	}
+	err := breakerCall(breaker, http.StatusOK)
+	var open *isbasi.CircuitOpenError
+	if !errors.Is(err, isbasi.ErrCircuitOpen) || !errors.As(err, &open) || open.Host != breakerHost {
+		t.Fatalf("got %v, want CircuitOpenError for %s", err, breakerHost)
+	}
+	if got := seen.get(); !slices.Equal(got, []string{"closed->open"}) {
+		t.Fatalf("got transitions %v", got)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker, _ := newBreaker()
+	breakerCall(breaker, http.StatusInternalServerError)
+	breakerCall(breaker, http.StatusOK)
+	breakerCall(breaker, http.StatusInternalServerError)
+	if state := breaker.State(breakerHost); state != isbasi.CircuitClosed {
+		t.Fatalf("got state %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	breaker, seen := newBreaker()
+	openBreaker(t, breaker)
+	time.Sleep(30 * time.Millisecond)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	probe := breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-release
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "https://"+breakerHost+"/firms", nil)
+		_, err := probe(req)
+		done <- err
+	}()
+	<-started
+	if state := breaker.State(breakerHost); state != isbasi.CircuitHalfOpen {
+		t.Fatalf("got state %s during probe, want half-open", state)
+	}
+	if err := breakerCall(breaker, http.StatusOK); !errors.Is(err, isbasi.ErrCircuitOpen) {
+		t.Fatalf("got %v while probe in flight, want ErrCircuitOpen", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if state := breaker.State(breakerHost); state != isbasi.CircuitClosed {
+		t.Fatalf("got state %s after successful probe, want closed", state)
+	}
+	if got := seen.get(); !slices.Equal(got, []string{"closed->open", "open->half-open", "half-open->closed"}) {
+		t.Fatalf("got transitions %v", got)
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	breaker, seen := newBreaker()
+	openBreaker(t, breaker)
+	time.Sleep(30 * time.Millisecond)
+	breakerCall(breaker, http.StatusInternalServerError)
+	if state := breaker.State(breakerHost); state != isbasi.CircuitOpen {
+		t.Fatalf("got state %s after failed probe, want open", state)
+	}
+	if err := breakerCall(breaker, http.StatusOK); !errors.Is(err, isbasi.ErrCircuitOpen) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+	if got := seen.get(); !slices.Equal(got, []string{"closed->open", "open->half-open", "half-open->open"}) {
+		t.Fatalf("got transitions %v", got)
+	}
+}
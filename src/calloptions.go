@@ -0,0 +1,75 @@
+package isbasi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+type CallOption func(*request)
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func WithLanguage(lang string) CallOption {
+	return func(r *request) {
+		r.language = lang
+	}
+}
+
+func WithTenant(tenantId string) CallOption {
+	return func(r *request) {
+		r.tenantId = tenantId
+	}
+}
+
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(r *request) {
+		r.timeout = timeout
+	}
+}
+
+func WithHeader(key, value string) CallOption {
+	return func(r *request) {
+		if r.header == nil {
+			r.header = make(http.Header)
+		}
+		r.header.Add(key, value)
+	}
+}
+
+func WithIdempotencyKey(key string) CallOption {
+	return func(r *request) {
+		r.idempotencyKey = key
+	}
+}
+
+func (r *request) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout > 0 {
+		return context.WithTimeout(ctx, r.timeout)
+	}
+	return ctx, func() {}
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (r *request) override(s *session) *session {
+	if r.language == "" && r.tenantId == "" {
+		return s
+	}
+	next := *s
+	if r.language != "" {
+		next.language = r.language
+	}
+	if r.tenantId != "" {
+		next.tenantId = r.tenantId
+	}
+	return &next
+}
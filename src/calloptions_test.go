@@ -0,0 +1,48 @@
+package isbasi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestNewRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		io.WriteString(w, `{"code":200}`)
+	}))
+	defer server.Close()
+	api := isbasi.Api("key")
+	api.SetBaseUrl(server.URL)
+	ctx := context.Background()
+
+	if _, err := api.NewRequest(ctx, "GET", "/slow", nil, isbasi.WithTimeout(20*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+
+	res, err := api.NewRequest(ctx, "GET", "/fast", nil, isbasi.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"code":200}` {
+		t.Fatalf("got body %q", data)
+	}
+}
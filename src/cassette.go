@@ -0,0 +1,225 @@
+package isbasi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+var ErrNoInteraction = errors.New("isbasi: no matching cassette interaction")
+
+var scrubbedHeaders = []string{"ApiKey", "Authorization", "Cookie", "Set-Cookie"}
+
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Tenant string      `json:"tenant,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type Matcher struct {
+	Method bool
+	Path   bool
+	Tenant bool
+	Body   bool
+}
+
+type Recorder struct {
+	next     Doer
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+type Replayer struct {
+	matcher  Matcher
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+var DefaultMatcher = Matcher{Method: true, Path: true, Tenant: true}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	cassette := new(Cassette)
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette: %w", err)
+	}
+	return cassette, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func NewRecorder(path string, next Doer) *Recorder {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	return &Recorder{next: next, path: path}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	res, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  redactQuery(req.URL.RawQuery),
+			Tenant: req.Header.Get("tenantId"),
+			Header: scrubHeader(req.Header),
+			Body:   string(redactBody(body)),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     scrubHeader(res.Header),
+			Body:       string(redactBody(data)),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return res, nil
+}
+
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]*Interaction(nil), r.cassette.Interactions...)}
+}
+
+func (r *Recorder) Save() error {
+	return r.Cassette().Save(r.path)
+}
+
+func NewReplayer(cassette *Cassette, matcher Matcher) *Replayer {
+	return &Replayer{matcher: matcher, cassette: cassette, used: make([]bool, len(cassette.Interactions))}
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = redactBody(data)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matches(&interaction.Request, req, body) {
+			continue
+		}
+		r.used[i] = true
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+}
+
+func (r *Replayer) matches(recorded *RecordedRequest, req *http.Request, body []byte) bool {
+	if r.matcher.Method && recorded.Method != req.Method {
+		return false
+	}
+	if r.matcher.Path && (recorded.Path != req.URL.Path || recorded.Query != redactQuery(req.URL.RawQuery)) {
+		return false
+	}
+	if r.matcher.Tenant && recorded.Tenant != req.Header.Get("tenantId") {
+		return false
+	}
+	if r.matcher.Body && !jsonEqual([]byte(recorded.Body), body) {
+		return false
+	}
+	return true
+}
+
+func scrubHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range scrubbedHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redacted)
+		}
+	}
+	return header
+}
+
+func jsonEqual(a, b []byte) bool {
+	x, err := decodeJSON(a)
+	if err != nil {
+		return bytes.Equal(a, b)
+	}
+	y, err := decodeJSON(b)
+	if err != nil {
+		return bytes.Equal(a, b)
+	}
+	ax, _ := json.Marshal(x)
+	by, _ := json.Marshal(y)
+	return bytes.Equal(ax, by)
+}
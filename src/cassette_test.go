@@ -0,0 +1,76 @@
+package isbasi_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+	"github.com/ozgur-yalcin/isbasi.go/src/isbasitest"
+)
+
+func TestCassetteRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := isbasi.NewRecorder(path, nil)
+	_, api := newClient(t, isbasi.WithDoer(recorder))
+	ctx := context.Background()
+	invoice := &isbasi.Invoice{
+		Customer:            &isbasi.Customer{Name: "Test"},
+		SalesInvoiceDetails: []*isbasi.SalesInvoiceDetail{{Name: "Line"}},
+		Extra:               map[string]json.RawMessage{"reference": []byte(`12345678901234567`)},
+	}
+	recorded, err := api.CreateInvoice(ctx, invoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cassette, err := isbasi.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := cassette.Interactions[len(cassette.Interactions)-1]
+	for _, body := range []string{last.Request.Body, last.Response.Body} {
+		if !strings.Contains(body, "12345678901234567") {
+			t.Fatalf("recorded body lost number precision: %s", body)
+		}
+	}
+	matcher := isbasi.DefaultMatcher
+	matcher.Body = true
+	replay := isbasi.Api("key", isbasi.WithDoer(isbasi.NewReplayer(cassette, matcher)))
+	replay.SetBaseUrl(api.BaseUrl())
+	if _, err := replay.Login(ctx, &isbasi.Login{Username: isbasitest.DefaultUsername, Password: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := replay.CreateInvoice(ctx, invoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Data.InvoiceId != recorded.Data.InvoiceId || string(replayed.Data.Extra["reference"]) != "12345678901234567" {
+		t.Fatalf("got invoice %d with reference %s, want %d with 12345678901234567", replayed.Data.InvoiceId, replayed.Data.Extra["reference"], recorded.Data.InvoiceId)
+	}
+}
+
+func TestCassetteRedactsQuery(t *testing.T) {
+	recorder := isbasi.NewRecorder("", nil)
+	_, api := newClient(t, isbasi.WithDoer(recorder))
+	ctx := context.Background()
+	filter := &isbasi.FirmFilter{TaxId: "1234567890"}
+	if _, err := api.ListFirms(ctx, filter); err != nil {
+		t.Fatal(err)
+	}
+	cassette := recorder.Cassette()
+	last := cassette.Interactions[len(cassette.Interactions)-1]
+	if strings.Contains(last.Request.Query, "1234567890") {
+		t.Fatalf("recorded query contains tax id: %s", last.Request.Query)
+	}
+	replay := isbasi.Api("key", isbasi.WithDoer(isbasi.NewReplayer(cassette, isbasi.DefaultMatcher)))
+	replay.SetBaseUrl(api.BaseUrl())
+	replay.SetAuthToken("token")
+	if _, err := replay.WithTenant(isbasitest.DefaultTenantId).ListFirms(ctx, filter); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,119 @@
+package isbasi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type CustomerMatch string
+
+const (
+	MatchedByTaxId  CustomerMatch = "taxId"
+	MatchedByCode   CustomerMatch = "code"
+	MatchedByName   CustomerMatch = "name"
+	CustomerCreated CustomerMatch = "created"
+)
+
+func (api *API) ResolveCustomer(ctx context.Context, customer *Customer, opts ...CallOption) (*Firm, CustomerMatch, error) {
+	if customer == nil {
+		return nil, "", fmt.Errorf("%w: customer is required", ErrValidation)
+	}
+	if taxId := normalizeTaxId(customer.TcknVkn); taxId != "" {
+		firms, err := api.FindFirmsByTaxId(ctx, taxId, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find customer by tax id: %w", err)
+		}
+		firm, err := singleFirm(firms, "tax id "+taxId)
+		if err != nil {
+			return nil, "", err
+		}
+		if firm != nil {
+			return firm, MatchedByTaxId, nil
+		}
+	}
+	if code := strings.TrimSpace(customer.Code); code != "" {
+		result, err := api.GetFirmByCode(ctx, code, opts...)
+		switch {
+		case err == nil && result.Data != nil && strings.TrimSpace(result.Data.Code) == code:
+			return result.Data, MatchedByCode, nil
+		case err != nil && !errors.Is(err, ErrNotFound):
+			return nil, "", fmt.Errorf("failed to find customer by code: %w", err)
+		}
+	}
+	firm, err := api.firmByName(ctx, customer.name(), opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find customer by name: %w", err)
+	}
+	if firm != nil {
+		return firm, MatchedByName, nil
+	}
+	firm = customer.firm()
+	result, err := api.CreateFirm(ctx, firm, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create customer: %w", err)
+	}
+	if result.Data != nil {
+		firm = result.Data
+	}
+	return firm, CustomerCreated, nil
+}
+
+func (api *API) firmByName(ctx context.Context, name string, opts ...CallOption) (*Firm, error) {
+	name = normalizeName(name)
+	if name == "" {
+		return nil, nil
+	}
+	search, _, _ := strings.Cut(name, " ")
+	var matches []*Firm
+	for firm, err := range api.AllFirms(ctx, &FirmFilter{Search: search}, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range []string{firm.Name, firm.FullName, firm.DisplayName, firm.FirstName + " " + firm.LastName} {
+			if normalizeName(candidate) == name {
+				matches = append(matches, firm)
+				break
+			}
+		}
+	}
+	return singleFirm(matches, "name "+strconv.Quote(name))
+}
+
+func (c *Customer) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.FirstName + " " + c.LastName
+}
+
+func (c *Customer) firm() *Firm {
+	return &Firm{
+		Code:         c.Code,
+		Name:         c.name(),
+		FirstName:    c.FirstName,
+		LastName:     c.LastName,
+		TcknVkn:      normalizeTaxId(c.TcknVkn),
+		TaxOffice:    c.TaxOffice,
+		Country:      c.Country,
+		City:         c.City,
+		District:     c.District,
+		Address:      c.Address,
+		EmailAddress: c.Email,
+		IsPersonal:   c.IsPersonal,
+	}
+}
+
+func normalizeName(name string) string {
+	name = strings.ToLowerSpecial(unicode.TurkishCase, name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
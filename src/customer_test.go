@@ -0,0 +1,48 @@
+package isbasi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestResolveCustomerChecksTaxId(t *testing.T) {
+	_, api := newClient(t, isbasi.WithMiddleware(ignoreQuery("taxOrPersonalId")))
+	ctx := context.Background()
+	other, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "A", Name: "Other", TcknVkn: "9999999999"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	firm, match, err := api.ResolveCustomer(ctx, &isbasi.Customer{Name: "New", TcknVkn: "1234567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match != isbasi.CustomerCreated || firm.Id == other.Data.Id {
+		t.Fatalf("got firm %d via %s, want a new firm", firm.Id, match)
+	}
+	firm, match, err = api.ResolveCustomer(ctx, &isbasi.Customer{TcknVkn: " 1234567890 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match != isbasi.MatchedByTaxId || firm.TcknVkn != "1234567890" {
+		t.Fatalf("got firm %d (%s) via %s, want tax id match", firm.Id, firm.TcknVkn, match)
+	}
+}
+
+func TestResolveCustomerAmbiguous(t *testing.T) {
+	_, api := newClient(t)
+	ctx := context.Background()
+	for _, code := range []string{"A", "B"} {
+		if _, err := api.CreateFirm(ctx, &isbasi.Firm{Code: code, Name: "Same A.Ş.", TcknVkn: "5555555555"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := api.ResolveCustomer(ctx, &isbasi.Customer{TcknVkn: "5555555555"}); !errors.Is(err, isbasi.ErrAmbiguousFirm) {
+		t.Fatalf("got %v, want ErrAmbiguousFirm for tax id", err)
+	}
+	if _, _, err := api.ResolveCustomer(ctx, &isbasi.Customer{Name: "same a ş"}); !errors.Is(err, isbasi.ErrAmbiguousFirm) {
+		t.Fatalf("got %v, want ErrAmbiguousFirm for name", err)
+	}
+}
@@ -0,0 +1,156 @@
+package isbasi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05"
+)
+
+var Istanbul = loadIstanbul()
+
+var zonedLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	dateLayout,
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+}
+
+type Date struct {
+	time.Time
+}
+
+type DateTime struct {
+	time.Time
+}
+
+func loadIstanbul() *time.Location {
+	if location, err := time.LoadLocation("Europe/Istanbul"); err == nil {
+		return location
+	}
+	return time.FixedZone("+03", 3*60*60)
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, Istanbul)}
+}
+
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{t}
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := parseTime(value)
+	if err != nil {
+		return Date{}, err
+	}
+	if t.IsZero() {
+		return Date{}, nil
+	}
+	t = t.In(Istanbul)
+	return NewDate(t.Year(), t.Month(), t.Day()), nil
+}
+
+func ParseDateTime(value string) (DateTime, error) {
+	t, err := parseTime(value)
+	return DateTime{t}, err
+}
+
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "0001-01-01") {
+		return time.Time{}, nil
+	}
+	for _, layout := range zonedLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, Istanbul); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("isbasi: unrecognized date %q", value)
+}
+
+func unquote(data []byte) (string, bool, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return "", false, nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.In(Istanbul).Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	value, ok, err := unquote(data)
+	if err != nil || !ok {
+		*d = Date{}
+		return err
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d DateTime) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.In(Istanbul).Format(dateTimeLayout)
+}
+
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	value, ok, err := unquote(data)
+	if err != nil || !ok {
+		*d = DateTime{}
+		return err
+	}
+	parsed, err := ParseDateTime(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
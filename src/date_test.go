@@ -0,0 +1,94 @@
+package isbasi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestDateUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"2024-01-01"`, "2024-01-01"},
+		{`"2024-01-01T00:00:00"`, "2024-01-01"},
+		{`"2024-01-01T23:59:59"`, "2024-01-01"},
+		{`"01.01.2024"`, "2024-01-01"},
+		{`"2024-01-01T21:30:00Z"`, "2024-01-02"},
+		{`"2024-01-01T23:30:00+03:00"`, "2024-01-01"},
+		{`null`, ""},
+		{`""`, ""},
+		{`"0001-01-01T00:00:00"`, ""},
+	}
+	for _, test := range tests {
+		var d isbasi.Date
+		if err := json.Unmarshal([]byte(test.input), &d); err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		if got := d.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.input, got, test.want)
+		}
+		if !d.IsZero() && d.Location() != isbasi.Istanbul {
+			t.Errorf("%s: got location %s, want Istanbul", test.input, d.Location())
+		}
+	}
+}
+
+func TestDateTimeUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{`"2024-01-01T00:00:00"`, time.Date(2024, 1, 1, 0, 0, 0, 0, isbasi.Istanbul)},
+		{`"2024-01-01T10:15:30.5"`, time.Date(2024, 1, 1, 10, 15, 30, 5e8, isbasi.Istanbul)},
+		{`"2024-01-01 10:15:30"`, time.Date(2024, 1, 1, 10, 15, 30, 0, isbasi.Istanbul)},
+		{`"2024-01-01T21:30:00Z"`, time.Date(2024, 1, 1, 21, 30, 0, 0, time.UTC)},
+		{`null`, time.Time{}},
+		{`"0001-01-01T00:00:00"`, time.Time{}},
+	}
+	for _, test := range tests {
+		var d isbasi.DateTime
+		if err := json.Unmarshal([]byte(test.input), &d); err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		if !d.Equal(test.want) {
+			t.Errorf("%s: got %v, want %v", test.input, d.Time, test.want)
+		}
+	}
+}
+
+func TestDateUnmarshalInvalid(t *testing.T) {
+	for _, input := range []string{`"yesterday"`, `1`} {
+		var d isbasi.Date
+		if err := json.Unmarshal([]byte(input), &d); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestDateMarshal(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{isbasi.NewDate(2024, 1, 2), `"2024-01-02"`},
+		{isbasi.Date{}, `null`},
+		{isbasi.NewDateTime(time.Date(2024, 1, 1, 21, 30, 0, 0, time.UTC)), `"2024-01-02T00:30:00"`},
+		{isbasi.NewDateTime(time.Date(2024, 1, 1, 10, 0, 0, 0, isbasi.Istanbul)), `"2024-01-01T10:00:00"`},
+		{isbasi.DateTime{}, `null`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%v: got %s, want %s", test.value, data, test.want)
+		}
+	}
+}
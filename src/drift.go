@@ -0,0 +1,123 @@
+package isbasi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var ErrSchemaDrift = errors.New("isbasi: schema drift")
+
+type SchemaDrift struct {
+	Method string
+	Path   string
+	Type   string
+	Fields []string
+}
+
+type SchemaDriftError struct {
+	Drifts []SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	messages := make([]string, 0, len(e.Drifts))
+	for _, drift := range e.Drifts {
+		messages = append(messages, fmt.Sprintf("%s: %s", drift.Type, strings.Join(drift.Fields, ", ")))
+	}
+	return fmt.Sprintf("%v: unknown fields in %s", ErrSchemaDrift, strings.Join(messages, "; "))
+}
+
+func (e *SchemaDriftError) Is(target error) bool {
+	return target == ErrSchemaDrift
+}
+
+func WithStrictDecoding() Option {
+	return func(api *API) {
+		api.strict = true
+	}
+}
+
+func WithDriftHandler(handler func(drift SchemaDrift)) Option {
+	return func(api *API) {
+		api.onDrift = handler
+	}
+}
+
+type driftSource interface {
+	unknownFields() map[string][]string
+}
+
+func (r *Response[T]) unknownFields() map[string][]string {
+	fields := make(map[string][]string)
+	addUnknown(fields, "Response", r.Extra)
+	collectUnknown(fields, reflect.ValueOf(r.Data))
+	return fields
+}
+
+func collectUnknown(fields map[string][]string, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectUnknown(fields, v.Index(i))
+		}
+	case reflect.Struct:
+		if holder, ok := v.Interface().(extraHolder); ok {
+			addUnknown(fields, v.Type().Name(), holder.extraFields())
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectUnknown(fields, v.Field(i))
+			}
+		}
+	}
+}
+
+func addUnknown(fields map[string][]string, typ string, extra map[string]json.RawMessage) {
+	for key := range extra {
+		if !slices.Contains(fields[typ], key) {
+			fields[typ] = append(fields[typ], key)
+		}
+	}
+}
+
+func (api *API) checkDrift(r *request, out any) error {
+	if !api.strict && api.onDrift == nil {
+		return nil
+	}
+	source, ok := out.(driftSource)
+	if !ok {
+		return nil
+	}
+	fields := source.unknownFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	types := make([]string, 0, len(fields))
+	for typ := range fields {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	drifts := make([]SchemaDrift, 0, len(types))
+	for _, typ := range types {
+		sort.Strings(fields[typ])
+		drift := SchemaDrift{Method: r.method, Path: r.path, Type: typ, Fields: fields[typ]}
+		if api.onDrift != nil {
+			api.onDrift(drift)
+		}
+		drifts = append(drifts, drift)
+	}
+	if api.strict {
+		return &SchemaDriftError{Drifts: drifts}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package isbasi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidEnum = errors.New("isbasi: invalid enum value")
+
+// ProductType values are documented in the İşbaşı integration examples
+// ("Ürün tipi (1: Mal, 2: Hizmet)") and are validated before sending.
+type ProductType int
+
+const (
+	ProductTypeGoods   ProductType = 1
+	ProductTypeService ProductType = 2
+)
+
+// The remaining code tables are not documented by İşbaşı, so no named
+// values are exported for them. Values are sent to the API unchanged and
+// only numeric forms are decoded.
+type (
+	PriceType           int
+	FirmType            int
+	EGovernmentType     int
+	EInvoiceProfile     int
+	InvoiceType         int
+	EArchivePaymentType int
+	EArchiveSendMode    int
+)
+
+var productTypeNames = map[ProductType]string{
+	ProductTypeGoods:   "Mal",
+	ProductTypeService: "Hizmet",
+}
+
+func enumString[T ~int](names map[T]string, v T) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.Itoa(int(v))
+}
+
+func enumValid[T ~int](names map[T]string, v T) bool {
+	_, ok := names[v]
+	return v == 0 || ok
+}
+
+func enumValidate[T ~int](names map[T]string, field string, v T) error {
+	if !enumValid(names, v) {
+		return fmt.Errorf("%w: %s %d", ErrInvalidEnum, field, int(v))
+	}
+	return nil
+}
+
+func enumUnmarshal[T ~int](names map[T]string, data []byte, v *T) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*v = T(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*v = 0
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		*v = T(n)
+		return nil
+	}
+	for value, name := range names {
+		if strings.EqualFold(name, s) {
+			*v = value
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidEnum, s)
+}
+
+func (t ProductType) String() string { return enumString(productTypeNames, t) }
+func (t ProductType) Valid() bool    { return enumValid(productTypeNames, t) }
+func (t *ProductType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal(productTypeNames, data, t)
+}
+
+func (t *PriceType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[PriceType](nil, data, t)
+}
+
+func (t *FirmType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[FirmType](nil, data, t)
+}
+
+func (t *EGovernmentType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[EGovernmentType](nil, data, t)
+}
+
+func (t *EInvoiceProfile) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[EInvoiceProfile](nil, data, t)
+}
+
+func (t *InvoiceType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[InvoiceType](nil, data, t)
+}
+
+func (t *EArchivePaymentType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[EArchivePaymentType](nil, data, t)
+}
+
+func (t *EArchiveSendMode) UnmarshalJSON(data []byte) error {
+	return enumUnmarshal[EArchiveSendMode](nil, data, t)
+}
+
+type validator interface {
+	Validate() error
+}
+
+func (d *ProductDetail) Validate() error {
+	if d == nil {
+		return nil
+	}
+	return enumValidate(productTypeNames, "itemType", d.ItemType)
+}
+
+func (p *Product) Validate() error {
+	if p == nil {
+		return nil
+	}
+	return enumValidate(productTypeNames, "type", p.Type)
+}
+
+func (i *Invoice) Validate() error {
+	if i == nil {
+		return nil
+	}
+	var errs []error
+	for _, detail := range i.SalesInvoiceDetails {
+		if detail != nil {
+			errs = append(errs, detail.ProductDetail.Validate())
+		}
+	}
+	return errors.Join(errs...)
+}
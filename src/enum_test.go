@@ -0,0 +1,36 @@
+package isbasi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestEnumValidation(t *testing.T) {
+	_, api := newClient(t)
+	ctx := context.Background()
+	if _, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "C1", Name: "Test", FirmType: 4}); err != nil {
+		t.Fatalf("undocumented firm type rejected: %v", err)
+	}
+	_, err := api.CreateProduct(ctx, &isbasi.Product{Code: "P1", Name: "Test", Type: 3})
+	if !errors.Is(err, isbasi.ErrInvalidEnum) {
+		t.Fatalf("got %v, want ErrInvalidEnum", err)
+	}
+}
+
+func TestEnumUnmarshal(t *testing.T) {
+	var product isbasi.Product
+	if err := json.Unmarshal([]byte(`{"type":"Hizmet"}`), &product); err != nil || product.Type != isbasi.ProductTypeService {
+		t.Fatalf("got %v, %v, want ProductTypeService", product.Type, err)
+	}
+	var firm isbasi.Firm
+	if err := json.Unmarshal([]byte(`{"firmType":"3"}`), &firm); err != nil || firm.FirmType != 3 {
+		t.Fatalf("got %d, %v, want 3", firm.FirmType, err)
+	}
+	if err := json.Unmarshal([]byte(`{"firmType":"Müşteri"}`), &firm); !errors.Is(err, isbasi.ErrInvalidEnum) {
+		t.Fatalf("got %v, want ErrInvalidEnum for an undocumented name", err)
+	}
+}
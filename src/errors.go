@@ -0,0 +1,94 @@
+package isbasi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnauthorized = errors.New("isbasi: unauthorized")
+	ErrNotFound     = errors.New("isbasi: not found")
+	ErrValidation   = errors.New("isbasi: validation failed")
+	ErrRateLimited  = errors.New("isbasi: rate limited")
+	ErrServer       = errors.New("isbasi: server error")
+)
+
+type APIError struct {
+	Code       int
+	Message    string
+	RequestId  string
+	StatusCode int
+	Endpoint   string
+	Method     string
+	Body       []byte
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("API error: %s %s: %s (status %d, code %d)", e.Method, e.Endpoint, message, e.StatusCode, e.Code)
+}
+
+func (e *APIError) Is(target error) bool {
+	return e.kind() == target
+}
+
+func (e *APIError) kind() error {
+	status := e.StatusCode
+	if status < http.StatusBadRequest && e.Code >= http.StatusBadRequest && e.Code < 600 {
+		status = e.Code
+	}
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return ErrValidation
+	}
+}
+
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func Attempts(err error) int {
+	if err == nil {
+		return 0
+	}
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		return retryErr.Attempts
+	}
+	return 1
+}
+
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("failed to execute request: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,226 @@
+package isbasi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrAmbiguousFirm = errors.New("isbasi: multiple firms match")
+
+type FirmPage struct {
+	Items      []*Firm `json:"items,omitempty"`
+	TotalCount int     `json:"totalCount,omitempty"`
+	Page       int     `json:"page,omitempty"`
+	PageSize   int     `json:"pageSize,omitempty"`
+}
+
+type FirmFilter struct {
+	IsActive      *bool
+	FirmType      FirmType
+	Tag           string
+	CategoryId    int
+	Search        string
+	TaxId         string
+	ModifiedSince time.Time
+	Page          int
+	PageSize      int
+}
+
+type FirmListResponse = Response[FirmPage]
+
+const defaultFirmPageSize = 100
+
+func (f *FirmFilter) query() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	if f.IsActive != nil {
+		query.Set("isActive", strconv.FormatBool(*f.IsActive))
+	}
+	if f.FirmType != 0 {
+		query.Set("firmType", strconv.Itoa(int(f.FirmType)))
+	}
+	if f.Tag != "" {
+		query.Set("tag", f.Tag)
+	}
+	if f.CategoryId != 0 {
+		query.Set("categoryId", strconv.Itoa(f.CategoryId))
+	}
+	if f.Search != "" {
+		query.Set("search", f.Search)
+	}
+	if f.TaxId != "" {
+		query.Set("taxOrPersonalId", f.TaxId)
+	}
+	if !f.ModifiedSince.IsZero() {
+		query.Set("modifiedSince", NewDateTime(f.ModifiedSince).String())
+	}
+	if f.Page > 0 {
+		query.Set("page", strconv.Itoa(f.Page))
+	}
+	if f.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(f.PageSize))
+	}
+	return query
+}
+
+func (api *API) UpdateFirm(ctx context.Context, req *Firm, opts ...CallOption) (result FirmResponse, err error) {
+	if req == nil || req.Id == 0 {
+		return result, fmt.Errorf("%w: firm id is required for update", ErrValidation)
+	}
+	err = api.Do(ctx, "POST", "/firms", req, &result, opts...)
+	return result, err
+}
+
+func (api *API) DeleteFirm(ctx context.Context, firmId int, opts ...CallOption) (result FirmResponse, err error) {
+	err = api.Do(ctx, "DELETE", fmt.Sprintf("/firms/%d", firmId), nil, &result, opts...)
+	return result, err
+}
+
+func (api *API) GetFirmByCode(ctx context.Context, code string, opts ...CallOption) (result FirmResponse, err error) {
+	err = api.Do(ctx, "GET", "/firms/code/"+url.PathEscape(code), nil, &result, opts...)
+	return result, err
+}
+
+func (api *API) UpsertFirm(ctx context.Context, req *Firm, opts ...CallOption) (result FirmResponse, err error) {
+	if req == nil {
+		return result, fmt.Errorf("%w: firm is required", ErrValidation)
+	}
+	existing, err := api.findFirm(ctx, req, opts...)
+	if err != nil {
+		return result, err
+	}
+	if existing == nil {
+		return api.CreateFirm(ctx, req, opts...)
+	}
+	update := *req
+	update.Id = existing.Id
+	return api.UpdateFirm(ctx, &update, opts...)
+}
+
+func (api *API) findFirm(ctx context.Context, req *Firm, opts ...CallOption) (*Firm, error) {
+	if req.Id != 0 {
+		return req, nil
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		result, err := api.GetFirmByCode(ctx, code, opts...)
+		switch {
+		case err == nil && result.Data != nil && strings.TrimSpace(result.Data.Code) == code:
+			return result.Data, nil
+		case err != nil && !errors.Is(err, ErrNotFound):
+			return nil, err
+		}
+	}
+	if taxId := normalizeTaxId(req.TcknVkn); taxId != "" {
+		firms, err := api.FindFirmsByTaxId(ctx, taxId, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return singleFirm(firms, "tax id "+taxId)
+	}
+	return nil, nil
+}
+
+func matchTaxId(firms []*Firm, taxId string) []*Firm {
+	var matches []*Firm
+	for _, firm := range firms {
+		if firm != nil && normalizeTaxId(firm.TcknVkn) == taxId {
+			matches = append(matches, firm)
+		}
+	}
+	return matches
+}
+
+func singleFirm(firms []*Firm, key string) (*Firm, error) {
+	switch len(firms) {
+	case 0:
+		return nil, nil
+	case 1:
+		return firms[0], nil
+	}
+	ids := make([]string, len(firms))
+	for i, firm := range firms {
+		ids[i] = strconv.Itoa(firm.Id)
+	}
+	return nil, fmt.Errorf("%w: %s matches firms %s", ErrAmbiguousFirm, key, strings.Join(ids, ", "))
+}
+
+func normalizeTaxId(taxId string) string {
+	return strings.Join(strings.Fields(taxId), "")
+}
+
+func (api *API) FindFirmsByTaxId(ctx context.Context, taxId string, opts ...CallOption) ([]*Firm, error) {
+	taxId = normalizeTaxId(taxId)
+	if taxId == "" {
+		return nil, fmt.Errorf("%w: tax id is required", ErrValidation)
+	}
+	var firms []*Firm
+	for firm, err := range api.AllFirms(ctx, &FirmFilter{TaxId: taxId}, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		firms = append(firms, firm)
+	}
+	return matchTaxId(firms, taxId), nil
+}
+
+func (api *API) ListFirms(ctx context.Context, filter *FirmFilter, opts ...CallOption) (result FirmListResponse, err error) {
+	path := "/firms"
+	if query := filter.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	err = api.Do(ctx, "GET", path, nil, &result, opts...)
+	return result, err
+}
+
+func (api *API) AllFirms(ctx context.Context, filter *FirmFilter, opts ...CallOption) iter.Seq2[*Firm, error] {
+	return func(yield func(*Firm, error) bool) {
+		page := FirmFilter{}
+		if filter != nil {
+			page = *filter
+		}
+		if page.Page < 1 {
+			page.Page = 1
+		}
+		if page.PageSize < 1 {
+			page.PageSize = defaultFirmPageSize
+		}
+		received := 0
+		ids := make(map[int]bool)
+		for {
+			result, err := api.ListFirms(ctx, &page, opts...)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if result.Data == nil || len(result.Data.Items) == 0 {
+				return
+			}
+			fresh := false
+			for _, firm := range result.Data.Items {
+				if firm == nil || ids[firm.Id] {
+					continue
+				}
+				if firm.Id != 0 {
+					ids[firm.Id] = true
+					fresh = true
+				}
+				if !yield(firm, nil) {
+					return
+				}
+			}
+			received += len(result.Data.Items)
+			if !fresh || len(result.Data.Items) < page.PageSize || (result.Data.TotalCount > 0 && received >= result.Data.TotalCount) {
+				return
+			}
+			page.Page++
+		}
+	}
+}
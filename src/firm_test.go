@@ -0,0 +1,83 @@
+package isbasi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestUpsertFirmIgnoresUnrelatedTaxId(t *testing.T) {
+	server, api := newClient(t, isbasi.WithMiddleware(ignoreQuery("taxOrPersonalId")))
+	ctx := context.Background()
+	other, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "A", Name: "Other", TcknVkn: "9999999999"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := api.UpsertFirm(ctx, &isbasi.Firm{Code: "B", Name: "New", TcknVkn: "1234567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Id == other.Data.Id {
+		t.Fatalf("upsert overwrote unrelated firm %d", other.Data.Id)
+	}
+	if firms := server.Firms(); len(firms) != 2 {
+		t.Fatalf("got %d firms, want 2", len(firms))
+	}
+}
+
+func TestUpsertFirmAmbiguousTaxId(t *testing.T) {
+	_, api := newClient(t)
+	ctx := context.Background()
+	for _, code := range []string{"A", "B"} {
+		if _, err := api.CreateFirm(ctx, &isbasi.Firm{Code: code, Name: code, TcknVkn: "5555555555"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := api.UpsertFirm(ctx, &isbasi.Firm{Name: "C", TcknVkn: "5555555555"})
+	if !errors.Is(err, isbasi.ErrAmbiguousFirm) {
+		t.Fatalf("got %v, want ErrAmbiguousFirm", err)
+	}
+}
+
+func TestAllFirmsStopsWhenPageIsIgnored(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.WriteString(w, `{"code":200,"data":{"items":[{"id":1,"name":"A"},{"id":2,"name":"B"}],"totalCount":0}}`)
+	}))
+	defer server.Close()
+	api := isbasi.Api("key")
+	api.SetBaseUrl(server.URL)
+	api.SetAuthToken("token")
+	n := 0
+	for _, err := range api.AllFirms(context.Background(), &isbasi.FirmFilter{PageSize: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 2 || calls.Load() != 2 {
+		t.Fatalf("got %d firms in %d calls, want 2 firms in 2 calls", n, calls.Load())
+	}
+}
+
+func TestListFirmsReportsDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"code":200,"data":{"items":[{"id":1,"name":"A","newField":true}],"totalCount":1}}`)
+	}))
+	defer server.Close()
+	api := isbasi.Api("key", isbasi.WithStrictDecoding())
+	api.SetBaseUrl(server.URL)
+	api.SetAuthToken("token")
+	_, err := api.ListFirms(context.Background(), nil)
+	var drift *isbasi.SchemaDriftError
+	if !errors.As(err, &drift) || len(drift.Drifts) != 1 || drift.Drifts[0].Type != "Firm" || drift.Drifts[0].Fields[0] != "newField" {
+		t.Fatalf("got %v, want drift on Firm.newField", err)
+	}
+}
@@ -0,0 +1,38 @@
+package isbasi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+	"github.com/ozgur-yalcin/isbasi.go/src/isbasitest"
+)
+
+func newServer(t testing.TB) *isbasitest.Server {
+	t.Helper()
+	server := isbasitest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newClient(t testing.TB, opts ...isbasi.Option) (*isbasitest.Server, *isbasi.API) {
+	t.Helper()
+	server := newServer(t)
+	api := server.Client(opts...)
+	if _, err := api.Login(context.Background(), server.Login()); err != nil {
+		t.Fatal(err)
+	}
+	return server, api
+}
+
+func ignoreQuery(key string) isbasi.Middleware {
+	return func(next isbasi.Handler) isbasi.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			query.Del(key)
+			req.URL.RawQuery = query.Encode()
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package isbasi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrAmbiguousInvoice = errors.New("isbasi: invoice may already exist")
+
+type LedgerEntry struct {
+	Key       string    `json:"key"`
+	InvoiceId int       `json:"invoiceId,omitempty"`
+	Pending   bool      `json:"pending,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type IdempotencyLedger interface {
+	Get(ctx context.Context, key string) (*LedgerEntry, error)
+	Put(ctx context.Context, entry *LedgerEntry) error
+	Delete(ctx context.Context, key string) error
+}
+
+type InvoiceLookup func(ctx context.Context, key string, invoice *Invoice) (invoiceId int, found bool, err error)
+
+type MemoryLedger struct {
+	mu      sync.Mutex
+	entries map[string]LedgerEntry
+}
+
+type FileLedger struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{entries: make(map[string]LedgerEntry)}
+}
+
+func (m *MemoryLedger) Get(ctx context.Context, key string) (*LedgerEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (m *MemoryLedger) Put(ctx context.Context, entry *LedgerEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[entry.Key] = *entry
+	return nil
+}
+
+func (m *MemoryLedger) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+func NewFileLedger(path string) *FileLedger {
+	return &FileLedger{path: path}
+}
+
+func (f *FileLedger) read() (map[string]LedgerEntry, error) {
+	entries := make(map[string]LedgerEntry)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode ledger: %w", err)
+	}
+	return entries, nil
+}
+
+func (f *FileLedger) write(entries map[string]LedgerEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode ledger: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return nil
+}
+
+func (f *FileLedger) Get(ctx context.Context, key string) (*LedgerEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (f *FileLedger) Put(ctx context.Context, entry *LedgerEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.read()
+	if err != nil {
+		return err
+	}
+	entries[entry.Key] = *entry
+	return f.write(entries)
+}
+
+func (f *FileLedger) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.read()
+	if err != nil {
+		return err
+	}
+	delete(entries, key)
+	return f.write(entries)
+}
+
+func WithIdempotencyLedger(ledger IdempotencyLedger) Option {
+	return func(api *API) {
+		api.ledger = ledger
+	}
+}
+
+func WithInvoiceLookup(lookup InvoiceLookup) Option {
+	return func(api *API) {
+		api.lookup = lookup
+	}
+}
+
+func (api *API) createInvoiceOnce(ctx context.Context, r *request, invoice *Invoice, result *InvoiceResponse) error {
+	key := r.idempotencyKey
+	entry, err := api.ledger.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read idempotency ledger: %w", err)
+	}
+	if entry != nil && entry.Pending {
+		if api.lookup == nil {
+			return fmt.Errorf("%w: idempotency key %q", ErrAmbiguousInvoice, key)
+		}
+		invoiceId, found, err := api.lookup(ctx, key, invoice)
+		if err != nil {
+			return fmt.Errorf("failed to look up invoice: %w", err)
+		}
+		if found {
+			entry = &LedgerEntry{Key: key, InvoiceId: invoiceId, UpdatedAt: time.Now()}
+			if err := api.ledger.Put(ctx, entry); err != nil {
+				return fmt.Errorf("failed to write idempotency ledger: %w", err)
+			}
+		}
+	}
+	if entry != nil && entry.InvoiceId != 0 {
+		created := *invoice
+		created.InvoiceId = entry.InvoiceId
+		*result = InvoiceResponse{Code: http.StatusOK, Data: &created}
+		return nil
+	}
+	if err := api.ledger.Put(ctx, &LedgerEntry{Key: key, Pending: true, UpdatedAt: time.Now()}); err != nil {
+		return fmt.Errorf("failed to write idempotency ledger: %w", err)
+	}
+	*result = InvoiceResponse{}
+	r.sent = false
+	err = api.attempt(ctx, r, result)
+	switch {
+	case result.Data != nil && result.Data.InvoiceId != 0:
+		if err := api.ledger.Put(ctx, &LedgerEntry{Key: key, InvoiceId: result.Data.InvoiceId, UpdatedAt: time.Now()}); err != nil {
+			return fmt.Errorf("failed to write idempotency ledger: %w", err)
+		}
+	case err != nil && !r.ambiguous(err):
+		if err := api.ledger.Delete(ctx, key); err != nil {
+			api.logError(ctx, "failed to clear idempotency ledger", err)
+		}
+	}
+	return err
+}
+
+func (r *request) ambiguous(err error) bool {
+	if !r.sent || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package isbasi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+	"github.com/ozgur-yalcin/isbasi.go/src/isbasitest"
+)
+
+const invoicesPath = "/invoices/integrationInvoices"
+
+func newLedgerClient(t *testing.T, opts ...isbasi.Option) (*isbasitest.Server, *isbasi.API, *isbasi.MemoryLedger) {
+	ledger := isbasi.NewMemoryLedger()
+	server, api := newClient(t, append(opts, isbasi.WithIdempotencyLedger(ledger))...)
+	return server, api, ledger
+}
+
+func TestLedgerClearedWhenNotSent(t *testing.T) {
+	breaker := isbasi.NewCircuitBreaker(isbasi.CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute})
+	server, api, ledger := newLedgerClient(t, isbasi.WithCircuitBreaker(breaker))
+	ctx := context.Background()
+	server.Inject(invoicesPath, isbasitest.Fault{Status: http.StatusInternalServerError, Times: 1})
+	if _, err := api.CreateInvoice(ctx, &isbasi.Invoice{}, isbasi.WithIdempotencyKey("k1")); err == nil {
+		t.Fatal("expected server error")
+	}
+	_, err := api.CreateInvoice(ctx, &isbasi.Invoice{}, isbasi.WithIdempotencyKey("k2"))
+	if !errors.Is(err, isbasi.ErrCircuitOpen) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+	if entry, _ := ledger.Get(ctx, "k2"); entry != nil {
+		t.Fatalf("got ledger entry %+v for a request that was never sent", entry)
+	}
+	if entry, _ := ledger.Get(ctx, "k1"); entry == nil || !entry.Pending {
+		t.Fatalf("got ledger entry %+v, want pending entry after a 500", entry)
+	}
+}
+
+func TestLedgerClearedOnLimiterError(t *testing.T) {
+	_, api, ledger := newLedgerClient(t, isbasi.WithRateLimit(0.001, 1))
+	ctx := context.Background()
+	api.CreateInvoice(ctx, &isbasi.Invoice{}, isbasi.WithIdempotencyKey("k1"))
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := api.CreateInvoice(short, &isbasi.Invoice{}, isbasi.WithIdempotencyKey("k2")); err == nil {
+		t.Fatal("expected rate limiter error")
+	}
+	if entry, _ := ledger.Get(ctx, "k2"); entry != nil {
+		t.Fatalf("got ledger entry %+v for a request that was never sent", entry)
+	}
+}
+
+func TestLedgerRecordsInvoiceOnSchemaDrift(t *testing.T) {
+	server, api, ledger := newLedgerClient(t, isbasi.WithStrictDecoding())
+	ctx := context.Background()
+	invoice := &isbasi.Invoice{
+		Customer:            &isbasi.Customer{Name: "Test"},
+		SalesInvoiceDetails: []*isbasi.SalesInvoiceDetail{{Name: "Line"}},
+		Extra:               map[string]json.RawMessage{"newField": []byte(`1`)},
+	}
+	res, err := api.CreateInvoice(ctx, invoice, isbasi.WithIdempotencyKey("k1"))
+	if !errors.Is(err, isbasi.ErrSchemaDrift) {
+		t.Fatalf("got %v, want ErrSchemaDrift", err)
+	}
+	entry, _ := ledger.Get(ctx, "k1")
+	if entry == nil || entry.Pending || entry.InvoiceId != res.Data.InvoiceId {
+		t.Fatalf("got ledger entry %+v, want invoice %d", entry, res.Data.InvoiceId)
+	}
+	again, err := api.CreateInvoice(ctx, invoice, isbasi.WithIdempotencyKey("k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Data.InvoiceId != res.Data.InvoiceId || len(server.Invoices()) != 1 {
+		t.Fatalf("got invoice %d and %d stored invoices, want %d and 1", again.Data.InvoiceId, len(server.Invoices()), res.Data.InvoiceId)
+	}
+}
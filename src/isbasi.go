@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,11 +20,31 @@ const (
 )
 
 type API struct {
-	BaseUrl   string
 	SecretKey string
-	TenantId  string
-	AuthToken string
-	Language  string
+	config
+	state  atomic.Pointer[session]
+	authMu sync.Mutex
+	mu     sync.RWMutex
+}
+
+type config struct {
+	client       Doer
+	transport    *http.Transport
+	timeout      time.Duration
+	credentials  CredentialProvider
+	retry        *RetryPolicy
+	limiter      *TenantRateLimiter
+	middleware   []Middleware
+	logger       *slog.Logger
+	logBodies    bool
+	logBodyLimit int
+	store        TokenStore
+	storeKey     string
+	ledger       IdempotencyLedger
+	lookup       InvoiceLookup
+	breaker      *CircuitBreaker
+	strict       bool
+	onDrift      func(drift SchemaDrift)
 }
 
 type Login struct {
@@ -30,13 +56,6 @@ type Login struct {
 	BaseUrl     string `json:"baseUrl,omitempty"`
 }
 
-type LoginResponse struct {
-	Code    int    `json:"code,omitempty"`
-	Message string `json:"message,omitempty"`
-	IsError bool   `json:"isError,omitempty"`
-	Data    *Login `json:"data,omitempty"`
-}
-
 type Customer struct {
 	Code       string `json:"code,omitempty"`
 	Name       string `json:"name,omitempty"`
@@ -47,37 +66,37 @@ type Customer struct {
 	City       string `json:"city,omitempty"`
 	District   string `json:"district,omitempty"`
 	Address    string `json:"address,omitempty"`
-	IsPersonal bool   `json:"isPerson,omitempty"`
+	IsPersonal *bool  `json:"isPerson,omitempty"`
 	FirstName  string `json:"firstName,omitempty"`
 	LastName   string `json:"lastName,omitempty"`
 }
 
 type ShipmentAgent struct {
-	Name       string `json:"name,omitempty"`
-	SurName    string `json:"surName,omitempty"`
-	Identifier string `json:"identifier,omitempty"`
-	FirmType   int    `json:"firmType,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	SurName    string   `json:"surName,omitempty"`
+	Identifier string   `json:"identifier,omitempty"`
+	FirmType   FirmType `json:"firmType,omitempty"`
 }
 
 type EGovernmentInvoice struct {
-	EGovernmentType        int    `json:"eGovernmentType,omitempty"`
-	InvoiceTypeForEinvoice int    `json:"invoiceTypeForEinvoice,omitempty"`
-	EInvoiceProfile        int    `json:"eInvoiceProfile,omitempty"`
-	EArchivePaymentType    int    `json:"eArchivePaymentType,omitempty"`
-	EArchivePaymentDate    string `json:"eArchivePaymentDate,omitempty"`
-	EArchivePaymentAgent   string `json:"eArchivePaymentAgent,omitempty"`
-	Website                string `json:"website,omitempty"`
+	EGovernmentType        EGovernmentType     `json:"eGovernmentType,omitempty"`
+	InvoiceTypeForEinvoice InvoiceType         `json:"invoiceTypeForEinvoice,omitempty"`
+	EInvoiceProfile        EInvoiceProfile     `json:"eInvoiceProfile,omitempty"`
+	EArchivePaymentType    EArchivePaymentType `json:"eArchivePaymentType,omitempty"`
+	EArchivePaymentDate    Date                `json:"eArchivePaymentDate,omitempty"`
+	EArchivePaymentAgent   string              `json:"eArchivePaymentAgent,omitempty"`
+	Website                string              `json:"website,omitempty"`
 }
 
 type EArchivePortalInvoice struct {
-	IsEArchive       bool `json:"isEArchive,omitempty"`
-	DispatchIncluded bool `json:"dispatchIncluded,omitempty"`
-	EGovernmentType  int  `json:"eGovernmentType,omitempty"`
+	IsEArchive       *bool           `json:"isEArchive,omitempty"`
+	DispatchIncluded *bool           `json:"dispatchIncluded,omitempty"`
+	EGovernmentType  EGovernmentType `json:"eGovernmentType,omitempty"`
 }
 
 type ProductDetail struct {
 	ItemCode    string       `json:"itemCode,omitempty"`
-	ItemType    int          `json:"itemType,omitempty"`
+	ItemType    ProductType  `json:"itemType,omitempty"`
 	Name        string       `json:"name,omitempty"`
 	Vat         float64      `json:"vat,omitempty"`
 	Unit        string       `json:"unit,omitempty"`
@@ -85,12 +104,12 @@ type ProductDetail struct {
 }
 
 type SalesInvoiceDetail struct {
-	Quantity         float64        `json:"quantity,omitempty"`
+	Quantity         Amount         `json:"quantity,omitempty"`
 	TaxRate          float64        `json:"taxRate,omitempty"`
 	Name             string         `json:"name,omitempty"`
-	Price            float64        `json:"price,omitempty"`
+	Price            Amount         `json:"price,omitempty"`
 	DiscountRate     float64        `json:"discountRate,omitempty"`
-	DiscountValue    float64        `json:"discountValue,omitempty"`
+	DiscountValue    Amount         `json:"discountValue,omitempty"`
 	StoppageRate     float64        `json:"stoppageRate,omitempty"`
 	VatExemptionCode string         `json:"vatExemptionCode,omitempty"`
 	Description      string         `json:"description,omitempty"`
@@ -108,7 +127,7 @@ type Unit struct {
 	Name              string  `json:"name,omitempty"`
 	ConversionFactor1 float64 `json:"conversionFactor1,omitempty"`
 	ConversionFactor2 float64 `json:"conversionFactor2,omitempty"`
-	IsMain            bool    `json:"isMain,omitempty"`
+	IsMain            *bool   `json:"isMain,omitempty"`
 	Barcode           string  `json:"barcode,omitempty"`
 	BarcodeId         int     `json:"barcodeId,omitempty"`
 	Code              string  `json:"code,omitempty"`
@@ -128,18 +147,18 @@ type UnitSet struct {
 }
 
 type Price struct {
-	PriceId          int     `json:"priceid,omitempty"`
-	Price            float64 `json:"price,omitempty"`
-	PriceTaxIncluded float64 `json:"priceTaxIncluded,omitempty"`
-	PriceTaxExcluded float64 `json:"priceTaxExcluded,omitempty"`
-	VatIncluded      bool    `json:"vatIncluded,omitempty"`
-	Currency         string  `json:"currency,omitempty"`
-	Code             string  `json:"code,omitempty"`
-	Unit             string  `json:"unit,omitempty"`
-	Type             int     `json:"type,omitempty"`
-	Description      string  `json:"description,omitempty"`
-	Message          string  `json:"message,omitempty"`
-	ExchangeRate     float64 `json:"exchangeRate,omitempty"`
+	PriceId          int       `json:"priceid,omitempty"`
+	Price            Amount    `json:"price,omitempty"`
+	PriceTaxIncluded Amount    `json:"priceTaxIncluded,omitempty"`
+	PriceTaxExcluded Amount    `json:"priceTaxExcluded,omitempty"`
+	VatIncluded      *bool     `json:"vatIncluded,omitempty"`
+	Currency         string    `json:"currency,omitempty"`
+	Code             string    `json:"code,omitempty"`
+	Unit             string    `json:"unit,omitempty"`
+	Type             PriceType `json:"type,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	Message          string    `json:"message,omitempty"`
+	ExchangeRate     Amount    `json:"exchangeRate,omitempty"`
 }
 
 type AdditionalTax struct {
@@ -165,7 +184,7 @@ type Withholding struct {
 type Image struct {
 	Id               int    `json:"id,omitempty"`
 	Image            string `json:"image,omitempty"`
-	IsImageSaveAsZip bool   `json:"isImageSaveAsZip,omitempty"`
+	IsImageSaveAsZip *bool  `json:"isImageSaveAsZip,omitempty"`
 }
 
 type Brand struct {
@@ -218,293 +237,445 @@ type EPortalLogin struct {
 }
 
 type Firm struct {
-	Id                         int                `json:"id,omitempty"`
-	Code                       string             `json:"code,omitempty"`
-	IsActive                   bool               `json:"isActive,omitempty"`
-	IsPersonal                 bool               `json:"isPersonalCompany,omitempty"`
-	Name                       string             `json:"name,omitempty"`
-	FirstName                  string             `json:"firstName,omitempty"`
-	LastName                   string             `json:"lastName,omitempty"`
-	FullName                   string             `json:"fullName,omitempty"`
-	DisplayName                string             `json:"displayName,omitempty"`
-	TcknVkn                    string             `json:"taxOrPersonalId,omitempty"`
-	TaxOffice                  string             `json:"taxOffice,omitempty"`
-	Country                    string             `json:"country,omitempty"`
-	City                       string             `json:"city,omitempty"`
-	ValidateCityAndDistrict    bool               `json:"validateCityAndDistrict,omitempty"`
-	District                   string             `json:"district,omitempty"`
-	PostalCode                 string             `json:"postalCode,omitempty"`
-	Address                    string             `json:"address,omitempty"`
-	Phone                      string             `json:"phone,omitempty"`
-	WebAddress                 string             `json:"webAddress,omitempty"`
-	Tags                       []string           `json:"tags,omitempty"`
-	Category                   *Category          `json:"category,omitempty"`
-	PhoneNumbers               []string           `json:"phoneNumbers,omitempty"`
-	EmailAddress               string             `json:"emailAddress,omitempty"`
-	Employees                  []*Employee        `json:"employees,omitempty"`
-	ShippingAddresses          []*ShippingAddress `json:"shippingAddresses,omitempty"`
-	Banks                      []*Bank            `json:"banks,omitempty"`
-	FaxNumber                  string             `json:"faxNumber,omitempty"`
-	Icon                       string             `json:"icon,omitempty"`
-	UserId                     string             `json:"UserId,omitempty"`
-	EInvoiceResponsible        bool               `json:"eInvoiceResponsible,omitempty"`
-	DefaultReportTemplate      string             `json:"defaultReportTemplate,omitempty"`
-	FirmType                   int                `json:"firmType,omitempty"`
-	EInvoiceProfile            int                `json:"eInvoiceProfile,omitempty"`
-	EInvoiceSenderLabel        string             `json:"eInvoiceSenderLabel,omitempty"`
-	EInvoicePostLabel          string             `json:"eInvoicePostLabel,omitempty"`
-	ELogoUserName              string             `json:"eLogoUserName,omitempty"`
-	ELogoPassword              string             `json:"eLogoPassword,omitempty"`
-	NaceCode                   string             `json:"naceCode,omitempty"`
-	EInvoiceControlType        int                `json:"eInvoiceControlType,omitempty"`
-	EInvoiceCustoms            bool               `json:"eInvoiceCustoms,omitempty"`
-	EInvoiceBrokerComp         int                `json:"eInvoiceBrokerComp,omitempty"`
-	EArchiveResponsible        bool               `json:"eArchiveResponsible,omitempty"`
-	EArchiveWebSite            string             `json:"eArchiveWebSite,omitempty"`
-	AdditionalInvoiceType      int                `json:"additionalInvoiceType,omitempty"`
-	SgkResponsibleCode         string             `json:"sgkResponsibleCode,omitempty"`
-	SgkResponsibleName         string             `json:"sgkResponsibleName,omitempty"`
-	EArchiveSendMod            int                `json:"eArchiveSendMod,omitempty"`
-	EGovermentType             int                `json:"eGovermentType,omitempty"`
-	ESmmResponsible            bool               `json:"eSmmResponsible,omitempty"`
-	ESmmSendMod                int                `json:"eSmmSendMod,omitempty"`
-	AcceptEinvPublic           int                `json:"acceptEinvPublic,omitempty"`
-	GenericCustomer            bool               `json:"genericCustomer,omitempty"`
-	NotApplyVat                bool               `json:"notApplyVat,omitempty"`
-	NotApplyWithHolding        bool               `json:"notApplyWithHolding,omitempty"`
-	NotApplyAdditionalTax      bool               `json:"notApplyAdditionalTax,omitempty"`
-	MersisNo                   string             `json:"mersisNo,omitempty"`
-	TradeRegisterNumber        string             `json:"tradeRegisterNumber,omitempty"`
-	PredefinedDescription      string             `json:"predefinedDescription,omitempty"`
-	IsAdmin                    bool               `json:"isAdmin,omitempty"`
-	IsCharteredAccountant      bool               `json:"isCharteredAccountant,omitempty"`
-	ErrorMessage               string             `json:"errorMessage,omitempty"`
-	BeginningBalance           float64            `json:"beginningBalance,omitempty"`
-	BeginningBalanceDate       time.Time          `json:"beginningBalanceDate,omitempty"`
-	Balance                    float64            `json:"balance,omitempty"`
-	CurrencyBalance            float64            `json:"currencyBalance,omitempty"`
-	CostMethodId               int                `json:"costMethodId,omitempty"`
-	Currency                   string             `json:"currency,omitempty"`
-	Description                string             `json:"description,omitempty"`
-	EInvoiceBeginDate          time.Time          `json:"eInvoiceBeginDate,omitempty"`
-	EArchiveBeginDate          time.Time          `json:"eArchiveBeginDate,omitempty"`
-	IsSendDispatchInEInvoice   bool               `json:"isSendDispatchInEInvoice,omitempty"`
-	SenderIbanBankAccountId    int                `json:"senderIbanBankAccountId,omitempty"`
-	ReceiptReadingMethod       int                `json:"purchaseServicesReceiptReadingMethod,omitempty"`
-	WasAccessPermissionGranted bool               `json:"wasAccessPermissionGranted,omitempty"`
-	BankAccount                *BankAccount       `json:"bankAccount,omitempty"`
-	ParentTenantSetDate        time.Time          `json:"parentTenantSetDate,omitempty"`
-	IsIntegrationFirm          bool               `json:"isIntegrationFirm,omitempty"`
-	HasApiAuthAuthority        bool               `json:"hasApiAuthAuthority,omitempty"`
-	EPortalArchiveResponsible  bool               `json:"ePortalArchiveResponsible,omitempty"`
-	EAPortalLoginInformation   *EPortalLogin      `json:"eAPortalLoginInformation,omitempty"`
+	Id                         int                        `json:"id,omitempty"`
+	Code                       string                     `json:"code,omitempty"`
+	IsActive                   *bool                      `json:"isActive,omitempty"`
+	IsPersonal                 *bool                      `json:"isPersonalCompany,omitempty"`
+	Name                       string                     `json:"name,omitempty"`
+	FirstName                  string                     `json:"firstName,omitempty"`
+	LastName                   string                     `json:"lastName,omitempty"`
+	FullName                   string                     `json:"fullName,omitempty"`
+	DisplayName                string                     `json:"displayName,omitempty"`
+	TcknVkn                    string                     `json:"taxOrPersonalId,omitempty"`
+	TaxOffice                  string                     `json:"taxOffice,omitempty"`
+	Country                    string                     `json:"country,omitempty"`
+	City                       string                     `json:"city,omitempty"`
+	ValidateCityAndDistrict    *bool                      `json:"validateCityAndDistrict,omitempty"`
+	District                   string                     `json:"district,omitempty"`
+	PostalCode                 string                     `json:"postalCode,omitempty"`
+	Address                    string                     `json:"address,omitempty"`
+	Phone                      string                     `json:"phone,omitempty"`
+	WebAddress                 string                     `json:"webAddress,omitempty"`
+	Tags                       []string                   `json:"tags,omitempty"`
+	Category                   *Category                  `json:"category,omitempty"`
+	PhoneNumbers               []string                   `json:"phoneNumbers,omitempty"`
+	EmailAddress               string                     `json:"emailAddress,omitempty"`
+	Employees                  []*Employee                `json:"employees,omitempty"`
+	ShippingAddresses          []*ShippingAddress         `json:"shippingAddresses,omitempty"`
+	Banks                      []*Bank                    `json:"banks,omitempty"`
+	FaxNumber                  string                     `json:"faxNumber,omitempty"`
+	Icon                       string                     `json:"icon,omitempty"`
+	UserId                     string                     `json:"UserId,omitempty"`
+	EInvoiceResponsible        *bool                      `json:"eInvoiceResponsible,omitempty"`
+	DefaultReportTemplate      string                     `json:"defaultReportTemplate,omitempty"`
+	FirmType                   FirmType                   `json:"firmType,omitempty"`
+	EInvoiceProfile            EInvoiceProfile            `json:"eInvoiceProfile,omitempty"`
+	EInvoiceSenderLabel        string                     `json:"eInvoiceSenderLabel,omitempty"`
+	EInvoicePostLabel          string                     `json:"eInvoicePostLabel,omitempty"`
+	ELogoUserName              string                     `json:"eLogoUserName,omitempty"`
+	ELogoPassword              string                     `json:"eLogoPassword,omitempty"`
+	NaceCode                   string                     `json:"naceCode,omitempty"`
+	EInvoiceControlType        int                        `json:"eInvoiceControlType,omitempty"`
+	EInvoiceCustoms            *bool                      `json:"eInvoiceCustoms,omitempty"`
+	EInvoiceBrokerComp         int                        `json:"eInvoiceBrokerComp,omitempty"`
+	EArchiveResponsible        *bool                      `json:"eArchiveResponsible,omitempty"`
+	EArchiveWebSite            string                     `json:"eArchiveWebSite,omitempty"`
+	AdditionalInvoiceType      int                        `json:"additionalInvoiceType,omitempty"`
+	SgkResponsibleCode         string                     `json:"sgkResponsibleCode,omitempty"`
+	SgkResponsibleName         string                     `json:"sgkResponsibleName,omitempty"`
+	EArchiveSendMod            EArchiveSendMode           `json:"eArchiveSendMod,omitempty"`
+	EGovermentType             EGovernmentType            `json:"eGovermentType,omitempty"`
+	ESmmResponsible            *bool                      `json:"eSmmResponsible,omitempty"`
+	ESmmSendMod                int                        `json:"eSmmSendMod,omitempty"`
+	AcceptEinvPublic           int                        `json:"acceptEinvPublic,omitempty"`
+	GenericCustomer            *bool                      `json:"genericCustomer,omitempty"`
+	NotApplyVat                *bool                      `json:"notApplyVat,omitempty"`
+	NotApplyWithHolding        *bool                      `json:"notApplyWithHolding,omitempty"`
+	NotApplyAdditionalTax      *bool                      `json:"notApplyAdditionalTax,omitempty"`
+	MersisNo                   string                     `json:"mersisNo,omitempty"`
+	TradeRegisterNumber        string                     `json:"tradeRegisterNumber,omitempty"`
+	PredefinedDescription      string                     `json:"predefinedDescription,omitempty"`
+	IsAdmin                    *bool                      `json:"isAdmin,omitempty"`
+	IsCharteredAccountant      *bool                      `json:"isCharteredAccountant,omitempty"`
+	ErrorMessage               string                     `json:"errorMessage,omitempty"`
+	BeginningBalance           Amount                     `json:"beginningBalance,omitempty"`
+	BeginningBalanceDate       DateTime                   `json:"beginningBalanceDate,omitempty"`
+	Balance                    Amount                     `json:"balance,omitempty"`
+	CurrencyBalance            Amount                     `json:"currencyBalance,omitempty"`
+	CostMethodId               int                        `json:"costMethodId,omitempty"`
+	Currency                   string                     `json:"currency,omitempty"`
+	Description                string                     `json:"description,omitempty"`
+	EInvoiceBeginDate          DateTime                   `json:"eInvoiceBeginDate,omitempty"`
+	EArchiveBeginDate          DateTime                   `json:"eArchiveBeginDate,omitempty"`
+	IsSendDispatchInEInvoice   *bool                      `json:"isSendDispatchInEInvoice,omitempty"`
+	SenderIbanBankAccountId    int                        `json:"senderIbanBankAccountId,omitempty"`
+	ReceiptReadingMethod       int                        `json:"purchaseServicesReceiptReadingMethod,omitempty"`
+	WasAccessPermissionGranted *bool                      `json:"wasAccessPermissionGranted,omitempty"`
+	BankAccount                *BankAccount               `json:"bankAccount,omitempty"`
+	ParentTenantSetDate        DateTime                   `json:"parentTenantSetDate,omitempty"`
+	IsIntegrationFirm          *bool                      `json:"isIntegrationFirm,omitempty"`
+	HasApiAuthAuthority        *bool                      `json:"hasApiAuthAuthority,omitempty"`
+	EPortalArchiveResponsible  *bool                      `json:"ePortalArchiveResponsible,omitempty"`
+	EAPortalLoginInformation   *EPortalLogin              `json:"eAPortalLoginInformation,omitempty"`
+	Extra                      map[string]json.RawMessage `json:"-"`
 }
 
 type Invoice struct {
-	InvoiceId                int                    `json:"invoiceId,omitempty"`
-	Customer                 *Customer              `json:"customer,omitempty"`
-	InvoiceDate              string                 `json:"invoiceDate,omitempty"`
-	Currency                 string                 `json:"currency,omitempty"`
-	ExchangeRate             float64                `json:"exchangeRate,omitempty"`
-	Description              string                 `json:"description,omitempty"`
-	CategoryName             string                 `json:"categoryName,omitempty"`
-	DeliveryAddressDifferent bool                   `json:"deliveryAddressDifferent,omitempty"`
-	VatIncluded              bool                   `json:"vatIncluded,omitempty"`
-	ShippingAddress          *ShippingAddress       `json:"shippingAddress,omitempty"`
-	SendingDate              string                 `json:"sendingDate,omitempty"`
-	ShipmentAgentItem        *ShipmentAgent         `json:"shipmentAgentItem,omitempty"`
-	EGovernmentInvoice       *EGovernmentInvoice    `json:"eGovernmentInvoice,omitempty"`
-	EArchivePortalInvoice    *EArchivePortalInvoice `json:"eArchivePortalInvoice,omitempty"`
-	SalesInvoiceDetails      []*SalesInvoiceDetail  `json:"salesInvoiceDetails,omitempty"`
+	InvoiceId                int                        `json:"invoiceId,omitempty"`
+	Customer                 *Customer                  `json:"customer,omitempty"`
+	InvoiceDate              Date                       `json:"invoiceDate,omitempty"`
+	Currency                 string                     `json:"currency,omitempty"`
+	ExchangeRate             Amount                     `json:"exchangeRate,omitempty"`
+	Description              string                     `json:"description,omitempty"`
+	CategoryName             string                     `json:"categoryName,omitempty"`
+	DeliveryAddressDifferent *bool                      `json:"deliveryAddressDifferent,omitempty"`
+	VatIncluded              *bool                      `json:"vatIncluded,omitempty"`
+	ShippingAddress          *ShippingAddress           `json:"shippingAddress,omitempty"`
+	SendingDate              Date                       `json:"sendingDate,omitempty"`
+	ShipmentAgentItem        *ShipmentAgent             `json:"shipmentAgentItem,omitempty"`
+	EGovernmentInvoice       *EGovernmentInvoice        `json:"eGovernmentInvoice,omitempty"`
+	EArchivePortalInvoice    *EArchivePortalInvoice     `json:"eArchivePortalInvoice,omitempty"`
+	SalesInvoiceDetails      []*SalesInvoiceDetail      `json:"salesInvoiceDetails,omitempty"`
+	Extra                    map[string]json.RawMessage `json:"-"`
 }
 
 type Product struct {
-	Id               int            `json:"id,omitempty"`
-	IsActive         bool           `json:"isActive,omitempty"`
-	Name             string         `json:"name,omitempty"`
-	Name2            string         `json:"name2,omitempty"`
-	IsReceiptProduct string         `json:"isReceiptProduct,omitempty"`
-	Code             string         `json:"code,omitempty"`
-	Type             int            `json:"type,omitempty"`
-	Tags             []string       `json:"tags,omitempty"`
-	Category         *Category      `json:"category,omitempty"`
-	Units            []*Unit        `json:"units,omitempty"`
-	UnitSet          *UnitSet       `json:"unitSet,omitempty"`
-	MainUnit         *Unit          `json:"mainUnit,omitempty"`
-	VatRate          float64        `json:"vatRate,omitempty"`
-	Prices           []*Price       `json:"prices,omitempty"`
-	AdditionalTax    *AdditionalTax `json:"additionalTax,omitempty"`
-	Withholding      *Withholding   `json:"withholding,omitempty"`
-	Images           []*Image       `json:"images,omitempty"`
-	ServiceGroupId   int            `json:"serviceGroupId,omitempty"`
-	ServiceGroupCode string         `json:"serviceGroupCode,omitempty"`
-	ServiceGroupName string         `json:"serviceGroupName,omitempty"`
-	ErrorMessage     string         `json:"errorMessage,omitempty"`
-	Brand            *Brand         `json:"brand,omitempty"`
-}
-
-type FirmResponse struct {
+	Id               int                        `json:"id,omitempty"`
+	IsActive         *bool                      `json:"isActive,omitempty"`
+	Name             string                     `json:"name,omitempty"`
+	Name2            string                     `json:"name2,omitempty"`
+	IsReceiptProduct string                     `json:"isReceiptProduct,omitempty"`
+	Code             string                     `json:"code,omitempty"`
+	Type             ProductType                `json:"type,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`
+	Category         *Category                  `json:"category,omitempty"`
+	Units            []*Unit                    `json:"units,omitempty"`
+	UnitSet          *UnitSet                   `json:"unitSet,omitempty"`
+	MainUnit         *Unit                      `json:"mainUnit,omitempty"`
+	VatRate          float64                    `json:"vatRate,omitempty"`
+	Prices           []*Price                   `json:"prices,omitempty"`
+	AdditionalTax    *AdditionalTax             `json:"additionalTax,omitempty"`
+	Withholding      *Withholding               `json:"withholding,omitempty"`
+	Images           []*Image                   `json:"images,omitempty"`
+	ServiceGroupId   int                        `json:"serviceGroupId,omitempty"`
+	ServiceGroupCode string                     `json:"serviceGroupCode,omitempty"`
+	ServiceGroupName string                     `json:"serviceGroupName,omitempty"`
+	ErrorMessage     string                     `json:"errorMessage,omitempty"`
+	Brand            *Brand                     `json:"brand,omitempty"`
+	Extra            map[string]json.RawMessage `json:"-"`
+}
+
+type Response[T any] struct {
+	Code    int                        `json:"code,omitempty"`
+	Message string                     `json:"message,omitempty"`
+	IsError bool                       `json:"isError,omitempty"`
+	Data    *T                         `json:"data,omitempty"`
+	Meta    *Meta                      `json:"-"`
+	Extra   map[string]json.RawMessage `json:"-"`
+}
+
+type LoginResponse = Response[Login]
+
+type FirmResponse = Response[Firm]
+
+type InvoiceResponse = Response[Invoice]
+
+type ProductResponse = Response[Product]
+
+type envelope struct {
 	Code    int    `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 	IsError bool   `json:"isError,omitempty"`
-	Data    *Firm  `json:"data,omitempty"`
-}
-
-type InvoiceResponse struct {
-	Code    int      `json:"code,omitempty"`
-	Message string   `json:"message,omitempty"`
-	IsError bool     `json:"isError,omitempty"`
-	Data    *Invoice `json:"data,omitempty"`
 }
 
-type ProductResponse struct {
-	Code    int      `json:"code,omitempty"`
-	Message string   `json:"message,omitempty"`
-	IsError bool     `json:"isError,omitempty"`
-	Data    *Product `json:"data,omitempty"`
-}
-
-func Api(secretKey string) *API {
+func Api(secretKey string, opts ...Option) *API {
 	client := new(API)
-	client.BaseUrl = baseUrl
 	client.SecretKey = secretKey
-	client.Language = "tr-TR"
+	client.state.Store(defaultSession())
+	client.transport = newTransport()
+	client.timeout = defaultTimeout
+	for _, opt := range opts {
+		opt(client)
+	}
+	if client.client == nil {
+		client.client = &http.Client{Transport: client.transport, Timeout: client.timeout}
+	}
 	return client
 }
 
+func (api *API) httpClient() Doer {
+	if api.client == nil {
+		return http.DefaultClient
+	}
+	return api.client
+}
+
 func (api *API) SetBaseUrl(url string) {
-	api.BaseUrl = url
+	api.update(func(s *session) {
+		s.baseUrl = url
+	})
 }
 
 func (api *API) SetLanguage(lang string) {
-	api.Language = lang
+	api.update(func(s *session) {
+		s.language = lang
+	})
+}
+
+type request struct {
+	method         string
+	path           string
+	payload        []byte
+	login          bool
+	idempotencyKey string
+	language       string
+	tenantId       string
+	timeout        time.Duration
+	header         http.Header
+	start          time.Time
+	attempts       int
+	sent           bool
+	metaOut        *Meta
+}
+
+func newRequest(ctx context.Context, method, path string, body any, opts ...CallOption) (*request, error) {
+	r := &request{method: method, path: path, idempotencyKey: IdempotencyKeyFromContext(ctx), start: time.Now()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if v, ok := body.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		r.payload = data
+	}
+	return r, nil
 }
 
-func (api *API) NewRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	payload, err := json.Marshal(body)
+func (api *API) NewRequest(ctx context.Context, method, path string, body any, opts ...CallOption) (*http.Response, error) {
+	r, err := newRequest(ctx, method, path, body, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, method, api.BaseUrl+path, bytes.NewBuffer(payload))
+	ctx, cancel := r.context(ctx)
+	res, err := api.send(ctx, r, r.override(api.session()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		cancel()
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("ApiKey", api.SecretKey)
-	req.Header.Set("Authorization", "Bearer "+api.AuthToken)
-	req.Header.Set("tenantId", api.TenantId)
-	req.Header.Set("lang", api.Language)
-	client := new(http.Client)
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %v", err)
-	}
-	defer res.Body.Close()
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
 	return res, nil
 }
 
-func (api *API) Login(ctx context.Context, body *Login) (result LoginResponse, err error) {
-	payload, err := json.Marshal(body)
-	if err != nil {
-		return result, fmt.Errorf("failed to marshal login request: %v", err)
+func (api *API) send(ctx context.Context, r *request, s *session) (*http.Response, error) {
+	if api.limiter != nil {
+		if err := api.limiter.Wait(ctx, s.tenantId); err != nil {
+			return nil, fmt.Errorf("rate limiter: %w", err)
+		}
+	}
+	var payload io.Reader
+	if r.payload != nil {
+		payload = bytes.NewReader(r.payload)
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", api.BaseUrl+"/user/integrationLogin", bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, r.method, s.baseUrl+r.path, payload)
 	if err != nil {
-		return result, fmt.Errorf("failed to create login request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("ApiKey", api.SecretKey)
-	client := new(http.Client)
-	res, err := client.Do(req)
-	if err != nil {
-		return result, fmt.Errorf("failed to execute login request: %v", err)
+	if s.authToken != "" && !r.login {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
 	}
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode login response: %v", err)
+	if s.tenantId != "" && !r.login {
+		req.Header.Set("tenantId", s.tenantId)
 	}
-	if result.IsError {
-		return result, fmt.Errorf("login failed: %s", result.Message)
+	req.Header.Set("lang", s.language)
+	if r.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", r.idempotencyKey)
 	}
-	api.AuthToken = result.Data.AccessToken
-	api.TenantId = result.Data.TenantId
-	if result.Data.BaseUrl != "" {
-		api.BaseUrl = result.Data.BaseUrl
+	for key, values := range r.header {
+		req.Header[key] = values
 	}
-	return result, nil
+	r.sent = true
+	res, err := api.handler()(req)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	return res, nil
 }
 
-func (api *API) CreateFirm(ctx context.Context, req *Firm) (result FirmResponse, err error) {
-	res, err := api.NewRequest(ctx, "PUT", "/firms", req)
+func (api *API) Do(ctx context.Context, method, path string, in, out any, opts ...CallOption) error {
+	r, err := newRequest(ctx, method, path, in, opts...)
 	if err != nil {
-		return result, err
+		return err
 	}
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response: %v", err)
-	}
-	if result.IsError {
-		return result, fmt.Errorf("API error: %s", result.Message)
-	}
-	return result, nil
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	return api.do(ctx, r, out)
 }
 
-func (api *API) CreateInvoice(ctx context.Context, req *Invoice) (result InvoiceResponse, err error) {
-	res, err := api.NewRequest(ctx, "POST", "/invoices/integrationInvoices", req)
-	if err != nil {
-		return result, err
+func (api *API) do(ctx context.Context, r *request, out any) error {
+	if api.retry == nil || !r.idempotent() {
+		return api.attempt(ctx, r, out)
 	}
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response: %v", err)
+	return api.retry.run(ctx, func() error {
+		return api.attempt(ctx, r, out)
+	})
+}
+
+func (api *API) attempt(ctx context.Context, r *request, out any) error {
+	s := api.session()
+	if !r.login && api.store != nil && s.authToken == "" {
+		if err := api.restore(ctx); err != nil {
+			api.logError(ctx, "failed to restore session", err)
+		}
+		s = api.session()
 	}
-	if result.IsError {
-		return result, fmt.Errorf("API error: %s", result.Message)
+	credentials := api.credentialProvider()
+	if !r.login && credentials != nil && tokenExpired(s.authToken) {
+		if err := api.refresh(ctx, s.authToken); err != nil {
+			return err
+		}
+		s = api.session()
 	}
-	return result, nil
-}
-
-func (api *API) CreateProduct(ctx context.Context, req *Product) (result ProductResponse, err error) {
-	res, err := api.NewRequest(ctx, "PUT", "/products", req)
+	err := api.roundTrip(ctx, r, s, out)
+	if err != nil && !r.login && api.store != nil && errors.Is(err, ErrUnauthorized) {
+		if err := api.invalidate(ctx); err != nil {
+			api.logError(ctx, "failed to invalidate session", err)
+		}
+	}
+	if err != nil && !r.login && credentials != nil && errors.Is(err, ErrUnauthorized) {
+		r.sent = false
+		if err := api.refresh(ctx, s.authToken); err != nil {
+			return err
+		}
+		err = api.roundTrip(ctx, r, api.session(), out)
+	}
+	return err
+}
+
+func (api *API) roundTrip(ctx context.Context, r *request, s *session, out any) (err error) {
+	start := time.Now()
+	var status int
+	var env envelope
+	var data []byte
+	s = r.override(s)
+	reset(out)
+	defer func() {
+		api.logRequest(ctx, r, s, status, env.Code, data, time.Since(start), err)
+	}()
+	res, err := api.send(ctx, r, s)
 	if err != nil {
-		return result, err
+		r.record(nil, out)
+		return err
 	}
 	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response: %v", err)
+	status = res.StatusCode
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
 	}
-	if result.IsError {
-		return result, fmt.Errorf("API error: %s", result.Message)
+	decodeErr := json.Unmarshal(data, &env)
+	if decodeErr == nil && out != nil {
+		decodeErr = json.Unmarshal(data, out)
 	}
-	return result, nil
+	r.record(res, out)
+	if res.StatusCode >= http.StatusBadRequest || (decodeErr == nil && env.IsError) {
+		return &APIError{
+			Code:       env.Code,
+			Message:    env.Message,
+			RequestId:  requestId(res.Header),
+			StatusCode: res.StatusCode,
+			Endpoint:   r.path,
+			Method:     r.method,
+			Body:       data,
+			RetryAfter: retryAfter(res.Header.Get("Retry-After")),
+		}
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	return api.checkDrift(r, out)
 }
 
-func (api *API) GetFirm(ctx context.Context, firmId int) (result FirmResponse, err error) {
-	res, err := api.NewRequest(ctx, "GET", fmt.Sprintf("/firms/%d", firmId), nil)
+func reset(out any) {
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().SetZero()
+	}
+}
+
+func (api *API) Login(ctx context.Context, body *Login, opts ...CallOption) (result LoginResponse, err error) {
+	r, err := newRequest(ctx, "POST", "/user/integrationLogin", body, opts...)
 	if err != nil {
 		return result, err
 	}
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response: %v", err)
+	r.login = true
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	if err := api.do(ctx, r, &result); err != nil {
+		return result, fmt.Errorf("login failed: %w", err)
+	}
+	if result.Data == nil {
+		return result, fmt.Errorf("login failed: empty response")
 	}
-	if result.IsError {
-		return result, fmt.Errorf("API error: %s", result.Message)
+	api.update(func(s *session) {
+		s.authToken = result.Data.AccessToken
+		s.tenantId = result.Data.TenantId
+		if result.Data.BaseUrl != "" {
+			s.baseUrl = result.Data.BaseUrl
+		}
+	})
+	if api.store != nil {
+		if err := api.persist(ctx); err != nil {
+			api.logError(ctx, "failed to save session", err)
+		}
 	}
 	return result, nil
 }
 
-func (api *API) GetProduct(ctx context.Context, productId, productType int) (result ProductResponse, err error) {
-	res, err := api.NewRequest(ctx, "GET", fmt.Sprintf("/products/%d/%d", productId, productType), nil)
+func (api *API) CreateFirm(ctx context.Context, req *Firm, opts ...CallOption) (result FirmResponse, err error) {
+	err = api.Do(ctx, "PUT", "/firms", req, &result, opts...)
+	return result, err
+}
+
+func (api *API) CreateInvoice(ctx context.Context, req *Invoice, opts ...CallOption) (result InvoiceResponse, err error) {
+	r, err := newRequest(ctx, "POST", "/invoices/integrationInvoices", req, opts...)
 	if err != nil {
 		return result, err
 	}
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response: %v", err)
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	if api.ledger == nil || r.idempotencyKey == "" {
+		err = api.do(ctx, r, &result)
+		return result, err
 	}
-	if result.IsError {
-		return result, fmt.Errorf("API error: %s", result.Message)
+	if api.retry == nil || api.lookup == nil {
+		err = api.createInvoiceOnce(ctx, r, req, &result)
+		return result, err
 	}
-	return result, nil
+	err = api.retry.run(ctx, func() error {
+		return api.createInvoiceOnce(ctx, r, req, &result)
+	})
+	return result, err
+}
+
+func (api *API) CreateProduct(ctx context.Context, req *Product, opts ...CallOption) (result ProductResponse, err error) {
+	err = api.Do(ctx, "PUT", "/products", req, &result, opts...)
+	return result, err
+}
+
+func (api *API) GetFirm(ctx context.Context, firmId int, opts ...CallOption) (result FirmResponse, err error) {
+	err = api.Do(ctx, "GET", fmt.Sprintf("/firms/%d", firmId), nil, &result, opts...)
+	return result, err
+}
+
+func (api *API) GetProduct(ctx context.Context, productId int, productType ProductType, opts ...CallOption) (result ProductResponse, err error) {
+	err = api.Do(ctx, "GET", fmt.Sprintf("/products/%d/%d", productId, productType), nil, &result, opts...)
+	return result, err
 }
@@ -0,0 +1,105 @@
+package isbasi_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func newBenchServer(b *testing.B) (*httptest.Server, *atomic.Int64) {
+	conns := new(atomic.Int64)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"code":200,"message":"Success","data":{"accessToken":"token","tenantId":"1"}}`)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	b.Cleanup(server.Close)
+	return server, conns
+}
+
+func benchClients(server *httptest.Server) map[string]*isbasi.API {
+	noKeepAlive := server.Client().Transport.(*http.Transport).Clone()
+	noKeepAlive.DisableKeepAlives = true
+	clients := map[string]*isbasi.API{
+		"reuse":    isbasi.Api("key"),
+		"no-reuse": isbasi.Api("key", isbasi.WithHTTPClient(&http.Client{Transport: noKeepAlive})),
+	}
+	for _, api := range clients {
+		api.SetBaseUrl(server.URL)
+	}
+	return clients
+}
+
+func reportConns(b *testing.B, name string, conns *atomic.Int64) {
+	opened := conns.Load()
+	b.ReportMetric(float64(opened), "conns")
+	if name == "reuse" && opened > 1 {
+		b.Fatalf("opened %d connections, want 1", opened)
+	}
+}
+
+func BenchmarkNewRequest(b *testing.B) {
+	for _, name := range []string{"reuse", "no-reuse"} {
+		b.Run(name, func(b *testing.B) {
+			server, conns := newBenchServer(b)
+			api := benchClients(server)[name]
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				res, err := api.NewRequest(ctx, "GET", "/firms/1", nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+			}
+			b.StopTimer()
+			reportConns(b, name, conns)
+		})
+	}
+}
+
+func BenchmarkLogin(b *testing.B) {
+	for _, name := range []string{"reuse", "no-reuse"} {
+		b.Run(name, func(b *testing.B) {
+			server, conns := newBenchServer(b)
+			api := benchClients(server)[name]
+			ctx := context.Background()
+			login := &isbasi.Login{Username: "user", Password: "pass"}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := api.Login(ctx, login); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			reportConns(b, name, conns)
+		})
+	}
+}
+
+func TestWithNilHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"code":200}`)
+	}))
+	defer server.Close()
+	api := isbasi.Api("key", isbasi.WithHTTPClient(nil))
+	api.SetBaseUrl(server.URL)
+	res, err := api.NewRequest(context.Background(), "GET", "/firms/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
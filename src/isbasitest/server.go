@@ -0,0 +1,455 @@
+package isbasitest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+const (
+	DefaultApiKey   = "test-api-key"
+	DefaultUsername = "test"
+	DefaultPassword = "test"
+	DefaultTenantId = "1"
+)
+
+type Fault struct {
+	Latency   time.Duration
+	Status    int
+	Malformed bool
+	Times     int
+}
+
+type Server struct {
+	*httptest.Server
+	ApiKey   string
+	Username string
+	Password string
+	TenantId string
+	Tenants  []*isbasi.Tenant
+	mu       sync.Mutex
+	nextId   int
+	tokens   map[string]string
+	faults   map[string][]*Fault
+	firms    map[int]*isbasi.Firm
+	products map[int]*isbasi.Product
+	invoices map[int]*isbasi.Invoice
+}
+
+func NewServer() *Server {
+	s := &Server{
+		ApiKey:   DefaultApiKey,
+		Username: DefaultUsername,
+		Password: DefaultPassword,
+		TenantId: DefaultTenantId,
+		Tenants:  []*isbasi.Tenant{{TenantId: DefaultTenantId, Name: "Test"}},
+		tokens:   make(map[string]string),
+		faults:   make(map[string][]*Fault),
+		firms:    make(map[int]*isbasi.Firm),
+		products: make(map[int]*isbasi.Product),
+		invoices: make(map[int]*isbasi.Invoice),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /user/integrationLogin", s.login)
+	mux.HandleFunc("GET /user/tenants", s.authorized(s.listTenants))
+	mux.HandleFunc("PUT /firms", s.authorized(s.createFirm))
+	mux.HandleFunc("POST /firms", s.authorized(s.updateFirm))
+	mux.HandleFunc("GET /firms", s.authorized(s.listFirms))
+	mux.HandleFunc("GET /firms/{id}", s.authorized(s.getFirm))
+	mux.HandleFunc("DELETE /firms/{id}", s.authorized(s.deleteFirm))
+	mux.HandleFunc("GET /firms/code/{code}", s.authorized(s.getFirmByCode))
+	mux.HandleFunc("PUT /products", s.authorized(s.createProduct))
+	mux.HandleFunc("GET /products/{id}/{type}", s.authorized(s.getProduct))
+	mux.HandleFunc("POST /invoices/integrationInvoices", s.authorized(s.createInvoice))
+	s.Server = httptest.NewServer(s.faulty(mux))
+	return s
+}
+
+func (s *Server) Client(opts ...isbasi.Option) *isbasi.API {
+	api := isbasi.Api(s.ApiKey, opts...)
+	api.SetBaseUrl(s.URL)
+	return api
+}
+
+func (s *Server) Login() *isbasi.Login {
+	return &isbasi.Login{Username: s.Username, Password: s.Password}
+}
+
+func (s *Server) Inject(path string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = append(s.faults[path], &fault)
+}
+
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string][]*Fault)
+}
+
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]string)
+}
+
+func (s *Server) Firms() []*isbasi.Firm {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return values(s.firms)
+}
+
+func (s *Server) Products() []*isbasi.Product {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return values(s.products)
+}
+
+func (s *Server) Invoices() []*isbasi.Invoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return values(s.invoices)
+}
+
+func (s *Server) id() int {
+	s.nextId++
+	return s.nextId
+}
+
+func (s *Server) fault(path string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range []string{path, ""} {
+		for i, fault := range s.faults[key] {
+			if fault.Times > 0 {
+				fault.Times--
+				if fault.Times == 0 {
+					s.faults[key] = append(s.faults[key][:i:i], s.faults[key][i+1:]...)
+				}
+			}
+			return fault
+		}
+	}
+	return nil
+}
+
+func (s *Server) faulty(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault := s.fault(r.URL.Path)
+		if fault == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		switch {
+		case fault.Malformed:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, `{"code":200,"data":`)
+		case fault.Status != 0:
+			writeError(w, fault.Status, http.StatusText(fault.Status))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("ApiKey") != s.ApiKey {
+			writeError(w, http.StatusUnauthorized, "Invalid api key")
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		tenantId, ok := s.tokens[token]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Token expired")
+			return
+		}
+		if header := r.Header.Get("tenantId"); header != "" && header != tenantId {
+			writeError(w, http.StatusUnauthorized, "Tenant mismatch")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("ApiKey") != s.ApiKey {
+		writeError(w, http.StatusUnauthorized, "Invalid api key")
+		return
+	}
+	var login isbasi.Login
+	if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if login.Username != s.Username || login.Password != s.Password {
+		writeError(w, http.StatusUnauthorized, "Invalid username or password")
+		return
+	}
+	tenantId := login.TenantId
+	if tenantId == "" {
+		tenantId = s.TenantId
+	}
+	token := newToken()
+	s.mu.Lock()
+	s.tokens[token] = tenantId
+	s.mu.Unlock()
+	writeData(w, &isbasi.Login{AccessToken: token, TenantId: tenantId})
+}
+
+func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	tenants := slices.Clone(s.Tenants)
+	s.mu.Unlock()
+	writeData(w, &tenants)
+}
+
+func (s *Server) createFirm(w http.ResponseWriter, r *http.Request) {
+	var firm isbasi.Firm
+	if !decode(w, r, &firm) {
+		return
+	}
+	if firm.Name == "" && firm.FirstName == "" {
+		writeError(w, http.StatusBadRequest, "Firm name is required")
+		return
+	}
+	s.mu.Lock()
+	firm.Id = s.id()
+	s.firms[firm.Id] = &firm
+	s.mu.Unlock()
+	writeData(w, &firm)
+}
+
+func (s *Server) getFirm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid firm id")
+		return
+	}
+	s.mu.Lock()
+	firm, ok := s.firms[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Firm not found")
+		return
+	}
+	writeData(w, firm)
+}
+
+func (s *Server) updateFirm(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	var update isbasi.Firm
+	if err := json.Unmarshal(body, &update); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.firms[update.Id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Firm not found")
+		return
+	}
+	firm := *existing
+	if err := json.Unmarshal(body, &firm); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	s.firms[firm.Id] = &firm
+	writeData(w, &firm)
+}
+
+func (s *Server) deleteFirm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid firm id")
+		return
+	}
+	s.mu.Lock()
+	firm, ok := s.firms[id]
+	delete(s.firms, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Firm not found")
+		return
+	}
+	writeData(w, firm)
+}
+
+func (s *Server) getFirmByCode(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	for _, firm := range s.Firms() {
+		if firm.Code == code {
+			writeData(w, firm)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Firm not found")
+}
+
+func (s *Server) listFirms(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	items := []*isbasi.Firm{}
+	for _, firm := range s.Firms() {
+		if matchFirm(firm, query) {
+			items = append(items, firm)
+		}
+	}
+	page, pageSize := positive(query.Get("page"), 1), positive(query.Get("pageSize"), len(items))
+	start, end := min((page-1)*pageSize, len(items)), min(page*pageSize, len(items))
+	writeData(w, &isbasi.FirmPage{Items: items[start:end], TotalCount: len(items), Page: page, PageSize: pageSize})
+}
+
+func matchFirm(firm *isbasi.Firm, query url.Values) bool {
+	if taxId := query.Get("taxOrPersonalId"); taxId != "" && firm.TcknVkn != taxId {
+		return false
+	}
+	if active := query.Get("isActive"); active != "" && strconv.FormatBool(isbasi.BoolValue(firm.IsActive)) != active {
+		return false
+	}
+	if firmType := query.Get("firmType"); firmType != "" && strconv.Itoa(int(firm.FirmType)) != firmType {
+		return false
+	}
+	if tag := query.Get("tag"); tag != "" && !slices.Contains(firm.Tags, tag) {
+		return false
+	}
+	if categoryId := query.Get("categoryId"); categoryId != "" && (firm.Category == nil || strconv.Itoa(firm.Category.Id) != categoryId) {
+		return false
+	}
+	if search := lower(query.Get("search")); search != "" && !strings.Contains(lower(firm.Name), search) && !strings.Contains(lower(firm.Code), search) {
+		return false
+	}
+	return true
+}
+
+func lower(s string) string {
+	return strings.ToLowerSpecial(unicode.TurkishCase, s)
+}
+
+func positive(value string, fallback int) int {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	return max(fallback, 1)
+}
+
+func (s *Server) createProduct(w http.ResponseWriter, r *http.Request) {
+	var product isbasi.Product
+	if !decode(w, r, &product) {
+		return
+	}
+	if product.Name == "" || product.Code == "" {
+		writeError(w, http.StatusBadRequest, "Product name and code are required")
+		return
+	}
+	s.mu.Lock()
+	product.Id = s.id()
+	s.products[product.Id] = &product
+	s.mu.Unlock()
+	writeData(w, &product)
+}
+
+func (s *Server) getProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+	s.mu.Lock()
+	product, ok := s.products[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+	writeData(w, product)
+}
+
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	var invoice isbasi.Invoice
+	if !decode(w, r, &invoice) {
+		return
+	}
+	if invoice.Customer == nil {
+		writeError(w, http.StatusBadRequest, "Customer is required")
+		return
+	}
+	if len(invoice.SalesInvoiceDetails) == 0 {
+		writeError(w, http.StatusBadRequest, "Invoice must have at least one line")
+		return
+	}
+	s.mu.Lock()
+	invoice.InvoiceId = s.id()
+	s.invoices[invoice.InvoiceId] = &invoice
+	s.mu.Unlock()
+	writeData(w, &invoice)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+func writeData[T any](w http.ResponseWriter, data *T) {
+	writeJSON(w, http.StatusOK, &isbasi.Response[T]{Code: http.StatusOK, Message: "Success", Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &isbasi.Response[struct{}]{Code: status, Message: message, IsError: true})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func values[T any](m map[int]*T) []*T {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	items := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, m[id])
+	}
+	return items
+}
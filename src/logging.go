@@ -0,0 +1,226 @@
+package isbasi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveKeys = map[string]bool{
+	"password":        true,
+	"elogopassword":   true,
+	"accesstoken":     true,
+	"appuserkey":      true,
+	"apikey":          true,
+	"secretkey":       true,
+	"authorization":   true,
+	"token":           true,
+	"taxorpersonalid": true,
+	"tcknvkn":         true,
+	"identifier":      true,
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(api *API) {
+		api.logger = logger
+	}
+}
+
+func WithBodyLogging(maxBytes int) Option {
+	return func(api *API) {
+		api.logBodies = true
+		api.logBodyLimit = maxBytes
+	}
+}
+
+func (api *API) logRequest(ctx context.Context, r *request, s *session, status int, code int, body []byte, elapsed time.Duration, err error) {
+	if api.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", r.method),
+		slog.String("path", redactPath(r.path)),
+		slog.Int("status", status),
+		slog.Duration("latency", elapsed),
+		slog.String("tenant", s.tenantId),
+		slog.Int("code", code),
+	}
+	if api.logBodies {
+		attrs = append(attrs, slog.String("request", api.logBody(r.payload)), slog.String("response", api.logBody(body)))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	api.logger.LogAttrs(ctx, level, "isbasi request", attrs...)
+}
+
+func (api *API) logError(ctx context.Context, msg string, err error) {
+	if api.logger == nil {
+		return
+	}
+	api.logger.LogAttrs(ctx, slog.LevelWarn, msg, slog.String("error", err.Error()))
+}
+
+func (api *API) logBody(body []byte) string {
+	text := string(redactBody(body))
+	if api.logBodyLimit > 0 && len(text) > api.logBodyLimit {
+		text = text[:api.logBodyLimit] + "..."
+	}
+	return text
+}
+
+func redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	value, err := decodeJSON(body)
+	if err != nil {
+		return body
+	}
+	value, changed := redactValue(value)
+	if !changed {
+		return body
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+func redactPath(path string) string {
+	path, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	return path + "?" + redactQuery(rawQuery)
+}
+
+func redactQuery(rawQuery string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	changed := false
+	for key, values := range query {
+		for i, value := range values {
+			if value != "" && (sensitiveKeys[strings.ToLower(key)] || isTckn(value)) {
+				values[i] = redacted
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return query.Encode()
+}
+
+func decodeJSON(data []byte) (any, error) {
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+func redactValue(value any) (any, bool) {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if sensitiveKeys[strings.ToLower(key)] {
+				if item != nil && item != "" {
+					v[key] = redacted
+					changed = true
+				}
+				continue
+			}
+			var ok bool
+			v[key], ok = redactValue(item)
+			changed = changed || ok
+		}
+	case []any:
+		for i, item := range v {
+			var ok bool
+			v[i], ok = redactValue(item)
+			changed = changed || ok
+		}
+	case string:
+		if isTckn(v) {
+			return redacted, true
+		}
+	}
+	return value, changed
+}
+
+func isTckn(value string) bool {
+	if len(value) != 11 || value[0] == '0' {
+		return false
+	}
+	var digits [11]int
+	for i := 0; i < 11; i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+		digits[i] = int(value[i] - '0')
+	}
+	odd := digits[0] + digits[2] + digits[4] + digits[6] + digits[8]
+	even := digits[1] + digits[3] + digits[5] + digits[7]
+	if ((odd*7-even)%10+10)%10 != digits[9] {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 10; i++ {
+		sum += digits[i]
+	}
+	return sum%10 == digits[10]
+}
+
+func (login Login) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("username", login.Username),
+		slog.String("password", redactString(login.Password)),
+		slog.String("appUserKey", redactString(login.UserKey)),
+		slog.String("accessToken", redactString(login.AccessToken)),
+		slog.String("tenantId", login.TenantId),
+		slog.String("baseUrl", login.BaseUrl),
+	)
+}
+
+func (login EPortalLogin) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("userId", login.UserId),
+		slog.String("password", redactString(login.Password)),
+	)
+}
+
+func (f Firm) LogValue() slog.Value {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return slog.StringValue(redacted)
+	}
+	return slog.StringValue(string(redactBody(data)))
+}
+
+func redactString(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
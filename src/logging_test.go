@@ -0,0 +1,58 @@
+package isbasi_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+const tckn = "10000000146"
+
+func TestLoggingRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	_, api := newClient(t, isbasi.WithLogger(logger), isbasi.WithBodyLogging(0))
+	firm := &isbasi.Firm{
+		Code:                     "C1",
+		Name:                     "Test",
+		Description:              tckn,
+		ELogoPassword:            "elogo-secret",
+		EAPortalLoginInformation: &isbasi.EPortalLogin{UserId: "user", Password: "portal-secret"},
+	}
+	if _, err := api.CreateFirm(context.Background(), firm); err != nil {
+		t.Fatal(err)
+	}
+	login := isbasi.Login{Username: "user", Password: "login-secret", UserKey: "key-secret", AccessToken: "token-secret"}
+	logger.Info("login", "login", login)
+	logger.Info("firm", "firm", firm)
+	output := buf.String()
+	if !strings.Contains(output, "isbasi request") {
+		t.Fatalf("no request logged: %s", output)
+	}
+	for _, secret := range []string{tckn, "elogo-secret", "portal-secret", "login-secret", "key-secret", "token-secret"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output contains %q", secret)
+		}
+	}
+}
+
+func TestLoggingRedactsQuery(t *testing.T) {
+	var buf bytes.Buffer
+	_, api := newClient(t, isbasi.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	if _, err := api.ListFirms(context.Background(), &isbasi.FirmFilter{TaxId: "1234567890", Search: tckn}); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "taxOrPersonalId=") {
+		t.Fatalf("no query logged: %s", output)
+	}
+	for _, secret := range []string{"1234567890", tckn} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output contains %q", secret)
+		}
+	}
+}
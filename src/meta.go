@@ -0,0 +1,55 @@
+package isbasi
+
+import (
+	"net/http"
+	"time"
+)
+
+var requestIdHeaders = []string{"X-Request-Id", "X-Correlation-Id", "Request-Id"}
+
+type Meta struct {
+	StatusCode int
+	Header     http.Header
+	RequestId  string
+	Latency    time.Duration
+	Attempts   int
+}
+
+type metaSetter interface {
+	setMeta(meta *Meta)
+}
+
+func (r *Response[T]) setMeta(meta *Meta) {
+	r.Meta = meta
+}
+
+func WithMeta(meta *Meta) CallOption {
+	return func(r *request) {
+		r.metaOut = meta
+	}
+}
+
+func (r *request) record(res *http.Response, out any) {
+	r.attempts++
+	meta := &Meta{Latency: time.Since(r.start), Attempts: r.attempts}
+	if res != nil {
+		meta.StatusCode = res.StatusCode
+		meta.Header = res.Header
+		meta.RequestId = requestId(res.Header)
+	}
+	if setter, ok := out.(metaSetter); ok {
+		setter.setMeta(meta)
+	}
+	if r.metaOut != nil {
+		*r.metaOut = *meta
+	}
+}
+
+func requestId(header http.Header) string {
+	for _, key := range requestIdHeaders {
+		if id := header.Get(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
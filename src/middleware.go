@@ -0,0 +1,73 @@
+package isbasi
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type Handler func(req *http.Request) (*http.Response, error)
+
+type Middleware func(next Handler) Handler
+
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(api *API) {
+		api.middleware = append(api.middleware, middleware...)
+	}
+}
+
+func (api *API) Use(middleware ...Middleware) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.middleware = append(slices.Clip(api.middleware), middleware...)
+}
+
+func (api *API) handler() Handler {
+	api.mu.RLock()
+	chain := api.middleware
+	api.mu.RUnlock()
+	handler := Handler(api.httpClient().Do)
+	if api.breaker != nil {
+		handler = api.breaker.Middleware()(handler)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return handler
+}
+
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			for key, values := range header {
+				req.Header[key] = values
+			}
+			return next(req)
+		}
+	}
+}
+
+func TimingMiddleware(observe func(req *http.Request, res *http.Response, elapsed time.Duration, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next(req)
+			observe(req, res, time.Since(start), err)
+			return res, err
+		}
+	}
+}
+
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return TimingMiddleware(func(req *http.Request, res *http.Response, elapsed time.Duration, err error) {
+		if err != nil {
+			logger.Printf("%s %s failed after %s: %v", req.Method, req.URL.Path, elapsed, err)
+			return
+		}
+		logger.Printf("%s %s %d %s", req.Method, req.URL.Path, res.StatusCode, elapsed)
+	})
+}
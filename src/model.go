@@ -0,0 +1,242 @@
+package isbasi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type zeroer interface {
+	IsZero() bool
+}
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func Bool(v bool) *bool {
+	return &v
+}
+
+func BoolValue(v *bool) bool {
+	return v != nil && *v
+}
+
+func marshalModel(v any) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	typ := value.Type()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	names := fieldNames(typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fieldValue := value.Field(i)
+		if strings.Contains(opts, "omitempty") && empty(fieldValue) {
+			continue
+		}
+		data, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	if holder, ok := v.(extraHolder); ok {
+		extra := holder.extraFields()
+		keys := make([]string, 0, len(extra))
+		for key := range extra {
+			if !names[strings.ToLower(key)] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			name, _ := json.Marshal(key)
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(extra[key])
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Map, reflect.Slice, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Struct:
+		if z, ok := v.Interface().(zeroer); ok {
+			return z.IsZero()
+		}
+		return false
+	}
+	return v.IsZero()
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	return marshalModel(p)
+}
+
+func (d SalesInvoiceDetail) MarshalJSON() ([]byte, error) {
+	return marshalModel(d)
+}
+
+func (e EGovernmentInvoice) MarshalJSON() ([]byte, error) {
+	return marshalModel(e)
+}
+
+func (f Firm) MarshalJSON() ([]byte, error) {
+	return marshalModel(f)
+}
+
+func (i Invoice) MarshalJSON() ([]byte, error) {
+	return marshalModel(i)
+}
+
+type extraHolder interface {
+	extraFields() map[string]json.RawMessage
+}
+
+var knownFields sync.Map
+
+func fieldNames(typ reflect.Type) map[string]bool {
+	if names, ok := knownFields.Load(typ); ok {
+		return names.(map[string]bool)
+	}
+	names := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	knownFields.Store(typ, names)
+	return names
+}
+
+func unknownFields(data []byte, typ reflect.Type) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	names := fieldNames(typ)
+	for key := range fields {
+		if names[strings.ToLower(key)] {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+func unmarshalModel(data []byte, v any, extra *map[string]json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	fields, err := unknownFields(data, reflect.TypeOf(v).Elem())
+	if err != nil {
+		return err
+	}
+	*extra = fields
+	return nil
+}
+
+func (f *Firm) UnmarshalJSON(data []byte) error {
+	type firm Firm
+	return unmarshalModel(data, (*firm)(f), &f.Extra)
+}
+
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type product Product
+	return unmarshalModel(data, (*product)(p), &p.Extra)
+}
+
+func (i *Invoice) UnmarshalJSON(data []byte) error {
+	type invoice Invoice
+	return unmarshalModel(data, (*invoice)(i), &i.Extra)
+}
+
+func (p Product) MarshalJSON() ([]byte, error) {
+	return marshalModel(p)
+}
+
+func (f Firm) extraFields() map[string]json.RawMessage {
+	return f.Extra
+}
+
+func (p Product) extraFields() map[string]json.RawMessage {
+	return p.Extra
+}
+
+func (i Invoice) extraFields() map[string]json.RawMessage {
+	return i.Extra
+}
+
+func (r *Response[T]) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		var err error
+		switch strings.ToLower(key) {
+		case "code":
+			err = json.Unmarshal(value, &r.Code)
+		case "message":
+			err = json.Unmarshal(value, &r.Message)
+		case "iserror":
+			err = json.Unmarshal(value, &r.IsError)
+		case "data":
+			r.Data = nil
+			if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+				r.Data = new(T)
+				err = json.Unmarshal(value, r.Data)
+			}
+		default:
+			if r.Extra == nil {
+				r.Extra = make(map[string]json.RawMessage)
+			}
+			r.Extra[key] = value
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package isbasi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestMarshalModel(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"empty firm", isbasi.Firm{}, `{}`},
+		{"nil bool omitted", isbasi.Firm{Name: "a"}, `{"name":"a"}`},
+		{"false bool kept", isbasi.Firm{Name: "a", IsActive: isbasi.Bool(false)}, `{"isActive":false,"name":"a"}`},
+		{"true bool kept", &isbasi.Product{IsActive: isbasi.Bool(true)}, `{"isActive":true}`},
+		{"zero amount omitted", isbasi.Price{Currency: "TRY"}, `{"currency":"TRY"}`},
+		{"amount kept", isbasi.Price{Price: isbasi.MustAmount("10.50"), ExchangeRate: isbasi.AmountFromInt(1)}, `{"price":10.5,"exchangeRate":1}`},
+		{"negative amount kept", isbasi.SalesInvoiceDetail{DiscountValue: isbasi.MustAmount("-1.25")}, `{"discountValue":-1.25}`},
+		{"zero date omitted", isbasi.Invoice{Description: "x"}, `{"description":"x"}`},
+		{"date kept", isbasi.Invoice{InvoiceDate: isbasi.NewDate(2025, 1, 2)}, `{"invoiceDate":"2025-01-02"}`},
+		{"zero date in nested struct omitted", isbasi.EGovernmentInvoice{Website: "w"}, `{"website":"w"}`},
+		{
+			"extra fields sorted after known fields",
+			isbasi.Firm{Code: "C1", Extra: map[string]json.RawMessage{"zeta": []byte(`1`), "alpha": []byte(`"a"`), "Beta": []byte(`true`)}},
+			`{"code":"C1","Beta":true,"alpha":"a","zeta":1}`,
+		},
+		{
+			"extra does not override known fields",
+			isbasi.Firm{Code: "C1", Extra: map[string]json.RawMessage{"CODE": []byte(`"X"`), "other": []byte(`2`)}},
+			`{"code":"C1","other":2}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalModelRoundTrip(t *testing.T) {
+	in := `{"code":"C1","isActive":false,"beginningBalance":0,"custom":{"a":1}}`
+	var firm isbasi.Firm
+	if err := json.Unmarshal([]byte(in), &firm); err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(firm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"code":"C1","isActive":false,"custom":{"a":1}}`
+	if string(got) != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package isbasi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultTimeout             = 30 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Option func(*API)
+
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = defaultMaxIdleConns
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	return transport
+}
+
+// WithHTTPClient and WithDoer replace the client built from the transport
+// options below; WithHTTPTimeout, WithMaxIdleConns*, WithIdleConnTimeout,
+// WithProxy*, WithTLSConfig and WithRootCAs have no effect on them. A nil
+// client keeps the default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(api *API) {
+		if client == nil {
+			api.client = nil
+			return
+		}
+		api.client = client
+	}
+}
+
+func WithDoer(doer Doer) Option {
+	return func(api *API) {
+		api.client = doer
+	}
+}
+
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(api *API) {
+		api.timeout = timeout
+	}
+}
+
+func WithMaxIdleConns(n int) Option {
+	return func(api *API) {
+		api.transport.MaxIdleConns = n
+	}
+}
+
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(api *API) {
+		api.transport.MaxIdleConnsPerHost = n
+	}
+}
+
+func WithMaxConnsPerHost(n int) Option {
+	return func(api *API) {
+		api.transport.MaxConnsPerHost = n
+	}
+}
+
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(api *API) {
+		api.transport.IdleConnTimeout = timeout
+	}
+}
+
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(api *API) {
+		api.transport.Proxy = proxy
+	}
+}
+
+func WithProxyURL(proxyUrl *url.URL) Option {
+	return WithProxy(http.ProxyURL(proxyUrl))
+}
+
+func WithTLSConfig(config *tls.Config) Option {
+	return func(api *API) {
+		api.transport.TLSClientConfig = config
+	}
+}
+
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(api *API) {
+		if api.transport.TLSClientConfig == nil {
+			api.transport.TLSClientConfig = new(tls.Config)
+		}
+		api.transport.TLSClientConfig.RootCAs = pool
+	}
+}
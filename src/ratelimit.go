@@ -0,0 +1,93 @@
+package isbasi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+type TenantRateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    int
+	limiters map[string]*RateLimiter
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func NewTenantRateLimiter(rate float64, burst int) *TenantRateLimiter {
+	return &TenantRateLimiter{rate: rate, burst: burst, limiters: make(map[string]*RateLimiter)}
+}
+
+func (t *TenantRateLimiter) Limiter(tenantId string) *RateLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limiter, ok := t.limiters[tenantId]
+	if !ok {
+		limiter = NewRateLimiter(t.rate, t.burst)
+		t.limiters[tenantId] = limiter
+	}
+	return limiter
+}
+
+func (t *TenantRateLimiter) Wait(ctx context.Context, tenantId string) error {
+	return t.Limiter(tenantId).Wait(ctx)
+}
+
+func WithRateLimit(rate float64, burst int) Option {
+	return func(api *API) {
+		api.limiter = NewTenantRateLimiter(rate, burst)
+	}
+}
+
+func WithRateLimiter(limiter *TenantRateLimiter) Option {
+	return func(api *API) {
+		api.limiter = limiter
+	}
+}
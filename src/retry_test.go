@@ -0,0 +1,103 @@
+package isbasi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+	"github.com/ozgur-yalcin/isbasi.go/src/isbasitest"
+)
+
+func fastRetry() *isbasi.RetryPolicy {
+	return &isbasi.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond, Multiplier: 2}
+}
+
+func newFlakyServer(t *testing.T, fail int, header http.Header) (*isbasi.API, *atomic.Int32) {
+	t.Helper()
+	calls := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(calls.Add(1)) <= fail {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"code":503,"message":"Unavailable","isError":true}`)
+			return
+		}
+		io.WriteString(w, `{"code":200,"message":"Success","data":{"id":1,"code":"C1"}}`)
+	}))
+	t.Cleanup(server.Close)
+	api := isbasi.Api("key", isbasi.WithRetryPolicy(fastRetry()))
+	api.SetBaseUrl(server.URL)
+	api.SetAuthToken("token")
+	return api, calls
+}
+
+func TestRetryReturnsCleanResponse(t *testing.T) {
+	server, api := newClient(t, isbasi.WithRetryPolicy(fastRetry()))
+	ctx := context.Background()
+	created, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "C1", Name: "Test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Inject("/firms/1", isbasitest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	res, err := api.GetFirm(ctx, created.Data.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError || res.Message != "Success" || res.Data == nil || res.Meta.Attempts != 2 {
+		t.Fatalf("got %+v (meta %+v), want a clean response after 2 attempts", res, res.Meta)
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	api, calls := newFlakyServer(t, 1, http.Header{"Retry-After": {"1"}})
+	start := time.Now()
+	if _, err := api.GetFirm(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("retried after %s, want at least Retry-After", elapsed)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("got %d calls, want 2", calls.Load())
+	}
+}
+
+func TestRetryStopsAtDeadline(t *testing.T) {
+	api, calls := newFlakyServer(t, 10, http.Header{"Retry-After": {"10"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := api.GetFirm(ctx, 1)
+	if !errors.Is(err, isbasi.ErrServer) {
+		t.Fatalf("got %v, want ErrServer", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("waited %s, want to give up before a wait past the deadline", elapsed)
+	}
+	if calls.Load() != 1 || isbasi.Attempts(err) != 1 {
+		t.Fatalf("got %d calls and %d attempts, want 1", calls.Load(), isbasi.Attempts(err))
+	}
+}
+
+func TestRetryAttempts(t *testing.T) {
+	api, calls := newFlakyServer(t, 10, nil)
+	_, err := api.GetFirm(context.Background(), 1)
+	var retryErr *isbasi.RetryError
+	if !errors.As(err, &retryErr) || !errors.Is(err, isbasi.ErrServer) {
+		t.Fatalf("got %v, want RetryError wrapping ErrServer", err)
+	}
+	if isbasi.Attempts(err) != 3 || calls.Load() != 3 {
+		t.Fatalf("got %d attempts and %d calls, want 3", isbasi.Attempts(err), calls.Load())
+	}
+	if isbasi.Attempts(nil) != 0 || isbasi.Attempts(errors.New("x")) != 1 {
+		t.Fatal("Attempts should be 0 for nil and 1 for a plain error")
+	}
+}
@@ -0,0 +1,83 @@
+package isbasi
+
+import "slices"
+
+type session struct {
+	baseUrl   string
+	tenantId  string
+	authToken string
+	language  string
+}
+
+func defaultSession() *session {
+	return &session{baseUrl: baseUrl, language: "tr-TR"}
+}
+
+func (api *API) session() *session {
+	if s := api.state.Load(); s != nil {
+		return s
+	}
+	return defaultSession()
+}
+
+func (api *API) update(fn func(s *session)) {
+	for {
+		current := api.state.Load()
+		var next session
+		if current != nil {
+			next = *current
+		} else {
+			next = *defaultSession()
+		}
+		fn(&next)
+		if api.state.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
+
+func (api *API) BaseUrl() string {
+	return api.session().baseUrl
+}
+
+func (api *API) TenantId() string {
+	return api.session().tenantId
+}
+
+func (api *API) AuthToken() string {
+	return api.session().authToken
+}
+
+func (api *API) Language() string {
+	return api.session().language
+}
+
+func (api *API) SetTenantId(tenantId string) {
+	api.update(func(s *session) {
+		s.tenantId = tenantId
+	})
+}
+
+func (api *API) SetAuthToken(token string) {
+	api.update(func(s *session) {
+		s.authToken = token
+	})
+}
+
+func (api *API) Clone() *API {
+	api.mu.RLock()
+	clone := &API{SecretKey: api.SecretKey, config: api.config}
+	api.mu.RUnlock()
+	clone.middleware = slices.Clip(clone.middleware)
+	clone.state.Store(api.session())
+	return clone
+}
+
+func (api *API) WithTenant(tenantId string) *API {
+	clone := api.Clone()
+	clone.SetTenantId(tenantId)
+	if clone.store != nil {
+		clone.storeKey = api.tokenKey() + "/" + tenantId
+	}
+	return clone
+}
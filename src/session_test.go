@@ -0,0 +1,59 @@
+package isbasi_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+	"github.com/ozgur-yalcin/isbasi.go/src/isbasitest"
+)
+
+func TestConcurrentUse(t *testing.T) {
+	server := newServer(t)
+	api := server.Client(isbasi.WithCredentials(isbasi.StaticCredentials(server.Login())))
+	ctx := context.Background()
+	created, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "C1", Name: "Test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passthrough := func(next isbasi.Handler) isbasi.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return next(req)
+		}
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := api
+			if i%2 == 0 {
+				client = api.WithTenant(isbasitest.DefaultTenantId)
+			}
+			for j := 0; j < 20; j++ {
+				if _, err := client.GetFirm(ctx, created.Data.Id); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			api.Use(passthrough)
+			api.SetCredentials(isbasi.StaticCredentials(server.Login()))
+			api.SetLanguage("tr-TR")
+			server.ExpireTokens()
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,173 @@
+package isbasi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultTenantConcurrency = 4
+	tenantsPath              = "/user/tenants"
+)
+
+type Tenant struct {
+	TenantId              string `json:"tenantId,omitempty"`
+	Name                  string `json:"name,omitempty"`
+	TcknVkn               string `json:"taxOrPersonalId,omitempty"`
+	IsCharteredAccountant bool   `json:"isCharteredAccountant,omitempty"`
+	IsAdmin               bool   `json:"isAdmin,omitempty"`
+}
+
+type TenantsResponse = Response[[]*Tenant]
+
+type TenantManager struct {
+	api         *API
+	login       *Login
+	concurrency int
+	mu          sync.Mutex
+	tenants     []*Tenant
+	clients     map[string]*API
+}
+
+type TenantErrors map[string]error
+
+func (e TenantErrors) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	messages := make([]string, 0, len(ids))
+	for _, id := range ids {
+		messages = append(messages, fmt.Sprintf("%s: %v", id, e[id]))
+	}
+	return fmt.Sprintf("%d tenant(s) failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+func (e TenantErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (api *API) ListTenants(ctx context.Context, opts ...CallOption) (result TenantsResponse, err error) {
+	err = api.Do(ctx, "GET", tenantsPath, nil, &result, opts...)
+	return result, err
+}
+
+func NewTenantManager(api *API, login *Login) *TenantManager {
+	return &TenantManager{
+		api:         api,
+		login:       login,
+		concurrency: defaultTenantConcurrency,
+		clients:     make(map[string]*API),
+	}
+}
+
+func (m *TenantManager) SetConcurrency(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.concurrency = n
+}
+
+func (m *TenantManager) Login(ctx context.Context) error {
+	if m.api.AuthToken() != "" {
+		return nil
+	}
+	_, err := m.api.Login(ctx, m.login)
+	return err
+}
+
+func (m *TenantManager) SetTenants(tenants []*Tenant) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tenants = tenants
+}
+
+func (m *TenantManager) Tenants(ctx context.Context) ([]*Tenant, error) {
+	m.mu.Lock()
+	tenants := m.tenants
+	m.mu.Unlock()
+	if tenants != nil {
+		return tenants, nil
+	}
+	if err := m.Login(ctx); err != nil {
+		return nil, err
+	}
+	result, err := m.api.ListTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenants = []*Tenant{}
+	if result.Data != nil {
+		tenants = *result.Data
+	}
+	m.mu.Lock()
+	m.tenants = tenants
+	m.mu.Unlock()
+	return tenants, nil
+}
+
+func (m *TenantManager) Client(tenantId string) *API {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if client, ok := m.clients[tenantId]; ok {
+		return client
+	}
+	client := m.api.WithTenant(tenantId)
+	if m.login != nil {
+		login := *m.login
+		login.TenantId = tenantId
+		client.SetAuthToken("")
+		client.credentials = StaticCredentials(&login)
+	}
+	m.clients[tenantId] = client
+	return client
+}
+
+func (m *TenantManager) ForEach(ctx context.Context, fn func(ctx context.Context, tenant *Tenant, api *API) error) error {
+	tenants, err := m.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	concurrency := m.concurrency
+	m.mu.Unlock()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(TenantErrors)
+	sem := make(chan struct{}, concurrency)
+	for _, tenant := range tenants {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs[tenant.TenantId] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(tenant *Tenant) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, tenant, m.Client(tenant.TenantId)); err != nil {
+				mu.Lock()
+				errs[tenant.TenantId] = err
+				mu.Unlock()
+			}
+		}(tenant)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package isbasi_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	isbasi "github.com/ozgur-yalcin/isbasi.go/src"
+)
+
+func TestTenantManagerForEach(t *testing.T) {
+	server := newServer(t)
+	server.Tenants = []*isbasi.Tenant{{TenantId: "1", Name: "A"}, {TenantId: "2", Name: "B"}, {TenantId: "3", Name: "C"}}
+	manager := isbasi.NewTenantManager(server.Client(), server.Login())
+	var mu sync.Mutex
+	seen := make(map[string]string)
+	err := manager.ForEach(context.Background(), func(ctx context.Context, tenant *isbasi.Tenant, api *isbasi.API) error {
+		if _, err := api.CreateFirm(ctx, &isbasi.Firm{Code: "T" + tenant.TenantId, Name: tenant.Name}); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		seen[tenant.TenantId] = api.TenantId()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tenant := range server.Tenants {
+		if seen[tenant.TenantId] != tenant.TenantId {
+			t.Errorf("tenant %s used client for %q", tenant.TenantId, seen[tenant.TenantId])
+		}
+	}
+}
+
+func TestTenantManagerSetTenants(t *testing.T) {
+	server := newServer(t)
+	manager := isbasi.NewTenantManager(server.Client(), server.Login())
+	manager.SetTenants([]*isbasi.Tenant{{TenantId: "9"}})
+	tenants, err := manager.Tenants(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 1 || tenants[0].TenantId != "9" {
+		t.Fatalf("got %+v, want tenant 9", tenants)
+	}
+}
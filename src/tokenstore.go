@@ -0,0 +1,227 @@
+package isbasi
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Session struct {
+	BaseUrl   string    `json:"baseUrl,omitempty"`
+	TenantId  string    `json:"tenantId,omitempty"`
+	AuthToken string    `json:"authToken,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+type TokenStore interface {
+	Load(ctx context.Context, key string) (*Session, error)
+	Save(ctx context.Context, key string, session *Session) error
+	Delete(ctx context.Context, key string) error
+}
+
+type MemoryTokenStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+func (s *Session) Valid() bool {
+	if s == nil || s.AuthToken == "" {
+		return false
+	}
+	if !s.ExpiresAt.IsZero() && time.Now().Add(tokenExpirySkew).After(s.ExpiresAt) {
+		return false
+	}
+	return !tokenExpired(s.AuthToken)
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{sessions: make(map[string]Session)}
+}
+
+func (m *MemoryTokenStore) Load(ctx context.Context, key string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[key]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (m *MemoryTokenStore) Save(ctx context.Context, key string, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[key] = *session
+	return nil
+}
+
+func (m *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, key)
+	return nil
+}
+
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{dir: dir}
+}
+
+func NewEncryptedFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	if len(key) == 0 {
+		return nil, errors.New("encryption key is empty")
+	}
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &FileTokenStore{dir: dir, aead: aead}, nil
+}
+
+func (f *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".session")
+}
+
+func (f *FileTokenStore) Load(ctx context.Context, key string) (*Session, error) {
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	if f.aead != nil {
+		size := f.aead.NonceSize()
+		if len(data) < size {
+			return nil, errors.New("failed to decrypt session: data too short")
+		}
+		data, err = f.aead.Open(nil, data[:size], data[size:], []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt session: %w", err)
+		}
+	}
+	session := new(Session)
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return session, nil
+}
+
+func (f *FileTokenStore) Save(ctx context.Context, key string, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if f.aead != nil {
+		nonce := make([]byte, f.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return fmt.Errorf("failed to encrypt session: %w", err)
+		}
+		data = f.aead.Seal(nonce, nonce, data, []byte(key))
+	}
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(f.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+func (f *FileTokenStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(f.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func WithTokenStore(store TokenStore, key string) Option {
+	return func(api *API) {
+		api.store = store
+		api.storeKey = key
+	}
+}
+
+func (api *API) tokenKey() string {
+	if api.storeKey != "" {
+		return api.storeKey
+	}
+	sum := sha256.Sum256([]byte(api.SecretKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (api *API) restore(ctx context.Context) error {
+	api.authMu.Lock()
+	defer api.authMu.Unlock()
+	if api.session().authToken != "" {
+		return nil
+	}
+	stored, err := api.store.Load(ctx, api.tokenKey())
+	if err != nil {
+		return err
+	}
+	if !stored.Valid() {
+		return nil
+	}
+	api.update(func(s *session) {
+		s.authToken = stored.AuthToken
+		if stored.TenantId != "" {
+			s.tenantId = stored.TenantId
+		}
+		if stored.BaseUrl != "" {
+			s.baseUrl = stored.BaseUrl
+		}
+	})
+	return nil
+}
+
+func (api *API) persist(ctx context.Context) error {
+	s := api.session()
+	stored := &Session{BaseUrl: s.baseUrl, TenantId: s.tenantId, AuthToken: s.authToken}
+	if exp, ok := tokenExpiry(s.authToken); ok {
+		stored.ExpiresAt = exp
+	}
+	return api.store.Save(ctx, api.tokenKey(), stored)
+}
+
+func (api *API) invalidate(ctx context.Context) error {
+	return api.store.Delete(ctx, api.tokenKey())
+}